@@ -0,0 +1,116 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// RecordKind tells what a record proves
+type RecordKind string
+
+const (
+	// KindConsent records what the registrant agreed to, it is appended before the order of the purchase is placed
+	KindConsent RecordKind = "CONSENT"
+	// KindOutcome records the order, or the error, of the purchase a consent record was appended for
+	KindOutcome RecordKind = "OUTCOME"
+)
+
+// Record is the proof of what a registrant agreed to when a domain was purchased. Records are chained: Hash covers
+// the record and the hash of the previous one, so that a record altered or removed afterwards is detected.
+type Record struct {
+	ID         string    `json:"id"`
+	RecordedAt time.Time `json:"recordedAt"`
+	// Kind is empty for the records written before consents were recorded ahead of their order, which hold both
+	Kind   RecordKind `json:"kind,omitempty"`
+	Domain string     `json:"domain"`
+	// AgreementKeys are the keys of the agreements consented to
+	AgreementKeys []string `json:"agreementKeys"`
+	// AgreementHashes are the SHA-256 of the content of each agreement consented to, by key
+	AgreementHashes map[string]string `json:"agreementHashes"`
+	// AgreedBy is the IP address of the end-user who consented, at AgreedAt
+	AgreedBy string `json:"agreedBy"`
+	AgreedAt string `json:"agreedAt"`
+	// Actor is the user who made the purchase on behalf of the end-user
+	Actor  string `json:"actor,omitempty"`
+	Tenant string `json:"tenant,omitempty"`
+	// ConsentID is the ID of the consent record an outcome record is the outcome of
+	ConsentID string `json:"consentId,omitempty"`
+	// OrderID is the GoDaddy order of the purchase, 0 when the purchase failed with Error
+	OrderID  int64  `json:"orderId,omitempty"`
+	Error    string `json:"error,omitempty"`
+	PrevHash string `json:"prevHash"`
+	Hash     string `json:"hash"`
+}
+
+// Filter selects records, its zero value selects them all
+type Filter struct {
+	Domain string
+	// Tenant only selects the records of purchases made on behalf of the tenant, records of every tenant when empty
+	Tenant string
+	// From and To bound RecordedAt, From is inclusive and To exclusive
+	From time.Time
+	To   time.Time
+}
+
+// Store persists records. Records can only be appended, never updated or deleted.
+type Store interface {
+	// Append sets the ID, RecordedAt and hashes of the record and persists it
+	Append(ctx context.Context, r Record) (Record, error)
+	Query(ctx context.Context, f Filter) ([]Record, error)
+}
+
+// HashContent returns the hash of an agreement content, as held in Record.AgreementHashes
+func HashContent(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// Matches tells whether the filter selects the record
+func (f Filter) Matches(r Record) bool {
+	if f.Domain != "" && f.Domain != r.Domain {
+		return false
+	}
+	if f.Tenant != "" && f.Tenant != r.Tenant {
+		return false
+	}
+	if !f.From.IsZero() && r.RecordedAt.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !r.RecordedAt.Before(f.To) {
+		return false
+	}
+	return true
+}
+
+// hash returns the chained hash of the record
+func (r Record) hash() (string, error) {
+	r.Hash = ""
+	b, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(append([]byte(r.PrevHash), b...))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// intact tells whether the record still has the hash it was appended with
+func (r Record) intact() bool {
+	hash, err := r.hash()
+	return err == nil && hash == r.Hash
+}
+
+type actorKey struct{}
+
+// WithActor returns a context carrying the user acting on behalf of the end-user
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the acting user carried by ctx, empty if there is none
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
@@ -0,0 +1,158 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/vendasta/gosdks/util"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// maxRecordSize bounds the size of a line of the audit file
+const maxRecordSize = 1 << 20
+
+// FileStore is a Store appending records to a JSON lines file
+type FileStore struct {
+	mu       sync.Mutex
+	path     string
+	file     *os.File
+	lastHash string
+}
+
+// NewFileStore opens the audit file at path, creating it if needed. The chain of the existing records is verified
+// so that records are never appended to a tampered file.
+func NewFileStore(path string) (*FileStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, util.Error(util.Internal, "Error creating directory of audit file %s: %s", path, err.Error())
+	}
+	s := &FileStore{path: path}
+	lastHash, err := s.verify()
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, util.Error(util.Internal, "Error opening audit file %s: %s", path, err.Error())
+	}
+	s.file = file
+	s.lastHash = lastHash
+	return s, nil
+}
+
+func (s *FileStore) Append(ctx context.Context, r Record) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r.ID = newID()
+	r.RecordedAt = time.Now().UTC()
+	r.PrevHash = s.lastHash
+	hash, err := r.hash()
+	if err != nil {
+		return Record{}, util.Error(util.Internal, "Error hashing audit record: %s", err.Error())
+	}
+	r.Hash = hash
+
+	line, err := json.Marshal(r)
+	if err != nil {
+		return Record{}, util.Error(util.Internal, "Error encoding audit record: %s", err.Error())
+	}
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return Record{}, util.Error(util.Internal, "Error writing audit record: %s", err.Error())
+	}
+	if err := s.file.Sync(); err != nil {
+		return Record{}, util.Error(util.Internal, "Error syncing audit file: %s", err.Error())
+	}
+	s.lastHash = hash
+	return r, nil
+}
+
+func (s *FileStore) Query(ctx context.Context, f Filter) ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records := []Record{}
+	err := s.scan(func(r Record) error {
+		if f.Matches(r) {
+			records = append(records, r)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// Verify checks that no record of the file was altered or removed
+func (s *FileStore) Verify(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.verify()
+	return err
+}
+
+// Close closes the audit file, records can no longer be appended
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.file.Close()
+}
+
+// verify checks the chain of the records and returns the hash of the last one
+func (s *FileStore) verify() (string, error) {
+	lastHash := ""
+	err := s.scan(func(r Record) error {
+		if r.PrevHash != lastHash || !r.intact() {
+			return util.Error(util.Internal, "Audit record %s of %s was tampered with", r.ID, s.path)
+		}
+		lastHash = r.Hash
+		return nil
+	})
+	return lastHash, err
+}
+
+// scan calls fn with each record of the file, in the order they were appended
+func (s *FileStore) scan(fn func(Record) error) error {
+	file, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return util.Error(util.Internal, "Error opening audit file %s: %s", s.path, err.Error())
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxRecordSize)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		r := Record{}
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return util.Error(util.Internal, "Error decoding audit record of %s: %s", s.path, err.Error())
+		}
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return util.Error(util.Internal, "Error reading audit file %s: %s", s.path, err.Error())
+	}
+	return nil
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().UTC().Format("20060102T150405.000000000")
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,145 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestFileStore(t *testing.T) *FileStore {
+	t.Helper()
+	s, err := NewFileStore(filepath.Join(t.TempDir(), "audit", "consent-audit.jsonl"))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func appendRecords(t *testing.T, s Store, domains ...string) []Record {
+	t.Helper()
+	records := make([]Record, 0, len(domains))
+	for _, domain := range domains {
+		r, err := s.Append(context.Background(), Record{Kind: KindConsent, Domain: domain, AgreedBy: "203.0.113.7"})
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+func TestFileStoreChain(t *testing.T) {
+	s := newTestFileStore(t)
+	records := appendRecords(t, s, "example.com", "example.ca", "example.com")
+
+	prevHash := ""
+	for _, r := range records {
+		if r.ID == "" || r.RecordedAt.IsZero() {
+			t.Errorf("got %+v, want the ID and time to be set", r)
+		}
+		if r.PrevHash != prevHash || !r.intact() {
+			t.Errorf("record %s is not chained to the previous one", r.ID)
+		}
+		prevHash = r.Hash
+	}
+	if err := s.Verify(context.Background()); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	// the chain goes on after the file is reopened
+	s.Close()
+	reopened, err := NewFileStore(s.path)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer reopened.Close()
+	next := appendRecords(t, reopened, "example.org")[0]
+	if next.PrevHash != prevHash {
+		t.Errorf("got previous hash %s, want %s", next.PrevHash, prevHash)
+	}
+}
+
+func TestFileStoreVerifyTamperedLine(t *testing.T) {
+	s := newTestFileStore(t)
+	appendRecords(t, s, "example.com", "example.ca")
+
+	content, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	tampered := bytes.Replace(content, []byte(`"agreedBy":"203.0.113.7"`), []byte(`"agreedBy":"198.51.100.1"`), 1)
+	if err := ioutil.WriteFile(s.path, tampered, 0600); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if err := s.Verify(context.Background()); err == nil {
+		t.Error("expected the tampered record to be detected")
+	}
+	if _, err := NewFileStore(s.path); err == nil {
+		t.Error("expected the tampered file not to be opened")
+	}
+}
+
+func TestFileStoreVerifyRemovedLine(t *testing.T) {
+	s := newTestFileStore(t)
+	appendRecords(t, s, "example.com", "example.ca", "example.org")
+
+	content, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	lines := bytes.SplitAfter(content, []byte("\n"))
+	if err := ioutil.WriteFile(s.path, append(lines[0], lines[2]...), 0600); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if err := s.Verify(context.Background()); err == nil {
+		t.Error("expected the removed record to be detected")
+	}
+}
+
+func TestFileStoreQuery(t *testing.T) {
+	s := newTestFileStore(t)
+	records := appendRecords(t, s, "example.com", "example.ca", "example.com")
+	from := records[1].RecordedAt
+	tenantRecord, err := s.Append(context.Background(), Record{Kind: KindConsent, Domain: "example.com", Tenant: "acme"})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	to := tenantRecord.RecordedAt
+
+	cases := []struct {
+		name   string
+		filter Filter
+		want   []Record
+	}{
+		{name: "all", filter: Filter{}, want: append(records, tenantRecord)},
+		{name: "domain", filter: Filter{Domain: "example.com", To: to}, want: []Record{records[0], records[2]}},
+		{name: "unknown domain", filter: Filter{Domain: "example.org"}, want: []Record{}},
+		{name: "tenant", filter: Filter{Tenant: "acme"}, want: []Record{tenantRecord}},
+		{name: "other tenant", filter: Filter{Tenant: "globex"}, want: []Record{}},
+		{name: "from is inclusive", filter: Filter{From: from, To: to}, want: records[1:]},
+		{name: "to is exclusive", filter: Filter{To: from}, want: records[:1]},
+		{name: "domain and period", filter: Filter{Domain: "example.com", From: from, To: time.Now().Add(time.Hour)}, want: []Record{records[2], tenantRecord}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := s.Query(context.Background(), c.filter)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if len(got) != len(c.want) {
+				t.Fatalf("got %d records, want %d", len(got), len(c.want))
+			}
+			for i := range got {
+				if got[i].ID != c.want[i].ID {
+					t.Errorf("record %d: got %s, want %s", i, got[i].ID, c.want[i].ID)
+				}
+			}
+		})
+	}
+}
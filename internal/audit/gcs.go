@@ -0,0 +1,414 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	httpService "github.com/glucn/godaddy/internal/http"
+	"github.com/glucn/godaddy/internal/redact"
+	"github.com/vendasta/gosdks/util"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	gcsBaseURL = "https://storage.googleapis.com"
+
+	// GCSScope is the OAuth2 scope the http client of a GCSStore must authenticate with
+	GCSScope = "https://www.googleapis.com/auth/devstorage.read_write"
+
+	// gcsSequenceDigits pads the position of a record in its object name, so that objects are listed in order
+	gcsSequenceDigits = 20
+
+	// maxAppendAttempts bounds how many times Append retries when other replicas keep appending first
+	maxAppendAttempts = 10
+)
+
+// GCSStore is a Store keeping each record in its own object of a Cloud Storage bucket, shared by every replica of the
+// server. An object is named after the position of its record in the chain and only created if it doesn't exist yet,
+// so that two replicas never append at the same position and a record is never overwritten. The bucket should have a
+// locked retention policy, so that records can't be deleted either.
+//
+// Neither finding the end of the chain nor querying it lists the whole bucket: the end is found by bisection, a time
+// range is mapped to a range of positions by bisection too, and the domain and tenant of each object are filtered on
+// from its metadata, so that only the records selected are downloaded.
+type GCSStore struct {
+	client  httpService.Interface
+	baseURL string
+	bucket  string
+	prefix  string
+
+	mu       sync.Mutex
+	lastSeq  int64
+	lastHash string
+	// lastRecordedAt keeps RecordedAt increasing along the chain despite the clock skew of the replicas, so that a
+	// time range maps to a range of positions
+	lastRecordedAt time.Time
+}
+
+// gcsObject is the metadata of a Cloud Storage object
+type gcsObject struct {
+	Name        string            `json:"name"`
+	ContentType string            `json:"contentType,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// NewGCSStore returns a store keeping the records in the bucket, under prefix. The http client must authenticate its
+// calls with GCSScope.
+func NewGCSStore(ctx context.Context, client httpService.Interface, bucket string, prefix string) (*GCSStore, error) {
+	return newGCSStore(ctx, client, gcsBaseURL, bucket, prefix)
+}
+
+func newGCSStore(ctx context.Context, client httpService.Interface, baseURL string, bucket string, prefix string) (*GCSStore, error) {
+	if bucket == "" {
+		return nil, util.Error(util.InvalidArgument, "Audit bucket must not be empty")
+	}
+	s := &GCSStore{client: client, baseURL: baseURL, bucket: bucket, prefix: prefix}
+
+	// the end of the chain is found here, later records appended by other replicas are caught up with
+	last, err := s.lastPosition(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if last == 0 {
+		return s, nil
+	}
+	r, err := s.get(ctx, last)
+	if err != nil {
+		return nil, err
+	}
+	if !r.intact() {
+		return nil, util.Error(util.Internal, "Audit record %s of bucket %s was tampered with", r.ID, bucket)
+	}
+	s.lastSeq, s.lastHash, s.lastRecordedAt = last, r.Hash, r.RecordedAt
+	return s, nil
+}
+
+// lastPosition returns the position of the last record of the chain, 0 if there is none, reading a number of records
+// logarithmic in the length of the chain. Positions have no gaps, records are never deleted.
+func (s *GCSStore) lastPosition(ctx context.Context) (int64, error) {
+	var found int64
+	missing := int64(1)
+	for {
+		ok, err := s.exists(ctx, missing)
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			break
+		}
+		found, missing = missing, missing*2
+	}
+	for missing-found > 1 {
+		mid := found + (missing-found)/2
+		ok, err := s.exists(ctx, mid)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			found = mid
+		} else {
+			missing = mid
+		}
+	}
+	return found, nil
+}
+
+// firstRecordedFrom returns the position of the first record of positions 1 to last recorded at or after t, last+1
+// if there is none
+func (s *GCSStore) firstRecordedFrom(ctx context.Context, t time.Time, last int64) (int64, error) {
+	lo, hi := int64(1), last+1
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		r, err := s.get(ctx, mid)
+		if err != nil {
+			return 0, err
+		}
+		if r.RecordedAt.Before(t) {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return lo, nil
+}
+
+func (s *GCSStore) Append(ctx context.Context, r Record) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r.ID = newID()
+	now := time.Now().UTC()
+	for attempt := 1; attempt <= maxAppendAttempts; attempt++ {
+		seq := s.lastSeq + 1
+		r.RecordedAt = now
+		if r.RecordedAt.Before(s.lastRecordedAt) {
+			r.RecordedAt = s.lastRecordedAt
+		}
+		r.PrevHash = s.lastHash
+		hash, err := r.hash()
+		if err != nil {
+			return Record{}, util.Error(util.Internal, "Error hashing audit record: %s", err.Error())
+		}
+		r.Hash = hash
+
+		err = s.create(ctx, seq, r)
+		if err == nil {
+			s.lastSeq, s.lastHash, s.lastRecordedAt = seq, r.Hash, r.RecordedAt
+			return r, nil
+		}
+		if !hasStatus(err, http.StatusPreconditionFailed) {
+			redact.Errorf(ctx, "Error creating audit object %s: %v", s.objectName(seq), err)
+			return Record{}, util.Error(util.Unavailable, "Error writing audit record")
+		}
+
+		// the position is taken, either by a retry of this very append or by another replica
+		existing, err := s.get(ctx, seq)
+		if err != nil {
+			return Record{}, err
+		}
+		if existing.ID == r.ID {
+			s.lastSeq, s.lastHash, s.lastRecordedAt = seq, existing.Hash, existing.RecordedAt
+			return existing, nil
+		}
+		if err := s.catchUp(ctx); err != nil {
+			return Record{}, err
+		}
+	}
+	return Record{}, util.Error(util.Aborted, "Too many concurrent appends to the audit trail")
+}
+
+func (s *GCSStore) Query(ctx context.Context, f Filter) ([]Record, error) {
+	s.mu.Lock()
+	err := s.catchUp(ctx)
+	last := s.lastSeq
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	// the time range is mapped to the range of positions holding it, so that only that range is listed
+	first, end := int64(1), last+1
+	if !f.From.IsZero() {
+		if first, err = s.firstRecordedFrom(ctx, f.From, last); err != nil {
+			return nil, err
+		}
+	}
+	if !f.To.IsZero() {
+		if end, err = s.firstRecordedFrom(ctx, f.To, last); err != nil {
+			return nil, err
+		}
+	}
+	records := []Record{}
+	if first >= end {
+		return records, nil
+	}
+
+	err = s.list(ctx, s.objectName(first), s.objectName(end), func(o gcsObject) error {
+		// the objects of other domains and tenants are skipped without downloading their record
+		if f.Domain != "" && o.Metadata["domain"] != f.Domain {
+			return nil
+		}
+		if tenant, ok := o.Metadata["tenant"]; ok && f.Tenant != "" && tenant != f.Tenant {
+			return nil
+		}
+		seq, ok := s.sequence(o.Name)
+		if !ok {
+			return nil
+		}
+		r, err := s.get(ctx, seq)
+		if err != nil {
+			return err
+		}
+		if f.Matches(r) {
+			records = append(records, r)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// Verify checks that no record of the bucket was altered or removed
+func (s *GCSStore) Verify(ctx context.Context) error {
+	var seq int64
+	lastHash := ""
+	return s.list(ctx, "", "", func(o gcsObject) error {
+		seq++
+		if got, ok := s.sequence(o.Name); !ok || got != seq {
+			return util.Error(util.Internal, "Audit record %d of bucket %s is missing", seq, s.bucket)
+		}
+		r, err := s.get(ctx, seq)
+		if err != nil {
+			return err
+		}
+		if r.PrevHash != lastHash || !r.intact() {
+			return util.Error(util.Internal, "Audit record %s of bucket %s was tampered with", r.ID, s.bucket)
+		}
+		lastHash = r.Hash
+		return nil
+	})
+}
+
+// catchUp moves the end of the chain past the records appended by other replicas, s.mu must be held
+func (s *GCSStore) catchUp(ctx context.Context) error {
+	for {
+		r, err := s.get(ctx, s.lastSeq+1)
+		if util.FromError(err).ErrorType() == util.NotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if r.PrevHash != s.lastHash || !r.intact() {
+			return util.Error(util.Internal, "Audit record %s of bucket %s was tampered with", r.ID, s.bucket)
+		}
+		s.lastSeq, s.lastHash, s.lastRecordedAt = s.lastSeq+1, r.Hash, r.RecordedAt
+	}
+}
+
+// objectName returns the name of the object holding the record at position seq of the chain, starting at 1
+func (s *GCSStore) objectName(seq int64) string {
+	return fmt.Sprintf("%srecords/%0*d.json", s.prefix, gcsSequenceDigits, seq)
+}
+
+// sequence returns the position of the record held by the object
+func (s *GCSStore) sequence(name string) (int64, bool) {
+	name = strings.TrimPrefix(name, s.prefix+"records/")
+	seq, err := strconv.ParseInt(strings.TrimSuffix(name, ".json"), 10, 64)
+	return seq, err == nil && seq > 0
+}
+
+// create creates the object of the record at position seq, failing with 412 when it already exists
+func (s *GCSStore) create(ctx context.Context, seq int64, r Record) error {
+	content, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	metadata, err := json.Marshal(gcsObject{
+		Name:        s.objectName(seq),
+		ContentType: httpService.ContentTypeJSON,
+		Metadata: map[string]string{
+			"domain":     r.Domain,
+			"kind":       string(r.Kind),
+			"recordedAt": r.RecordedAt.Format(time.RFC3339Nano),
+			"tenant":     r.Tenant,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	for _, part := range [][]byte{metadata, content} {
+		w, err := writer.CreatePart(textproto.MIMEHeader{"Content-Type": []string{httpService.ContentTypeJSON}})
+		if err != nil {
+			return err
+		}
+		w.Write(part)
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	// creating the object only if it doesn't exist makes retrying it safe
+	params := []httpService.URLParam{{Key: "uploadType", Value: "multipart"}, {Key: "ifGenerationMatch", Value: "0"}}
+	u := fmt.Sprintf("%s/upload/storage/v1/b/%s/o", s.baseURL, url.PathEscape(s.bucket))
+	res, err := s.client.Call(httpService.WithRetryNonIdempotent(ctx), http.MethodPost, u, body, "", "multipart/related; boundary="+writer.Boundary(), params)
+	if err != nil {
+		return err
+	}
+	httpService.DiscardBody(res)
+	return nil
+}
+
+// get returns the record at position seq of the chain
+func (s *GCSStore) get(ctx context.Context, seq int64) (Record, error) {
+	name := s.objectName(seq)
+	u := fmt.Sprintf("%s/storage/v1/b/%s/o/%s", s.baseURL, url.PathEscape(s.bucket), url.PathEscape(name))
+	res, err := s.client.Call(ctx, http.MethodGet, u, nil, "", "", []httpService.URLParam{{Key: "alt", Value: "media"}})
+	if hasStatus(err, http.StatusNotFound) {
+		return Record{}, util.Error(util.NotFound, "Audit record %d not found", seq)
+	}
+	if err != nil {
+		redact.Errorf(ctx, "Error reading audit object %s: %v", name, err)
+		return Record{}, util.Error(util.Unavailable, "Error reading audit record")
+	}
+
+	r := Record{}
+	if err := httpService.DecodeJSON(res, &r); err != nil {
+		redact.Errorf(ctx, "Error decoding audit object %s: %v", name, err)
+		return Record{}, util.Error(util.Internal, "Error decoding audit record")
+	}
+	return r, nil
+}
+
+// exists tells whether there is a record at position seq of the chain
+func (s *GCSStore) exists(ctx context.Context, seq int64) (bool, error) {
+	_, err := s.get(ctx, seq)
+	if util.FromError(err).ErrorType() == util.NotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// list calls fn with the record objects of the store named from startOffset and before endOffset, every one when
+// they are empty, in the order of the chain
+func (s *GCSStore) list(ctx context.Context, startOffset string, endOffset string, fn func(gcsObject) error) error {
+	u := fmt.Sprintf("%s/storage/v1/b/%s/o", s.baseURL, url.PathEscape(s.bucket))
+	pageToken := ""
+	for {
+		params := []httpService.URLParam{
+			{Key: "prefix", Value: s.prefix + "records/"},
+			{Key: "fields", Value: "items(name,metadata),nextPageToken"},
+		}
+		if startOffset != "" {
+			params = append(params, httpService.URLParam{Key: "startOffset", Value: startOffset})
+		}
+		if endOffset != "" {
+			params = append(params, httpService.URLParam{Key: "endOffset", Value: endOffset})
+		}
+		if pageToken != "" {
+			params = append(params, httpService.URLParam{Key: "pageToken", Value: pageToken})
+		}
+		res, err := s.client.Call(ctx, http.MethodGet, u, nil, "", "", params)
+		if err != nil {
+			redact.Errorf(ctx, "Error listing audit objects of bucket %s: %v", s.bucket, err)
+			return util.Error(util.Unavailable, "Error listing audit records")
+		}
+
+		page := struct {
+			Items         []gcsObject `json:"items"`
+			NextPageToken string      `json:"nextPageToken"`
+		}{}
+		if err := httpService.DecodeJSON(res, &page); err != nil {
+			redact.Errorf(ctx, "Error decoding audit objects of bucket %s: %v", s.bucket, err)
+			return util.Error(util.Internal, "Error listing audit records")
+		}
+		for _, o := range page.Items {
+			if err := fn(o); err != nil {
+				return err
+			}
+		}
+		if page.NextPageToken == "" {
+			return nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+// hasStatus tells whether err is an http error with the status code
+func hasStatus(err error, statusCode int) bool {
+	httpErr, ok := err.(*httpService.Error)
+	return ok && httpErr.StatusCode == statusCode
+}
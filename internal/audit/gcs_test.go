@@ -0,0 +1,265 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	httpService "github.com/glucn/godaddy/internal/http"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeGCS is a stand-in of the Cloud Storage JSON API holding the objects of a single bucket
+type fakeGCS struct {
+	mu       sync.Mutex
+	objects  map[string][]byte
+	metadata map[string]map[string]string
+	// lists and downloads count the calls listing objects and downloading records
+	lists     int
+	downloads int
+}
+
+func newFakeGCS(t *testing.T) (*fakeGCS, *httptest.Server) {
+	t.Helper()
+	f := &fakeGCS{objects: map[string][]byte{}, metadata: map[string]map[string]string{}}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func (f *fakeGCS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/upload/storage/v1/b/audit/o":
+		f.create(w, r)
+	case r.Method == http.MethodGet && r.URL.Path == "/storage/v1/b/audit/o":
+		f.lists++
+		f.list(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/storage/v1/b/audit/o/"):
+		f.downloads++
+		content, ok := f.objects[strings.TrimPrefix(r.URL.Path, "/storage/v1/b/audit/o/")]
+		if !ok || r.URL.Query().Get("alt") != "media" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(content)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeGCS) create(w http.ResponseWriter, r *http.Request) {
+	_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || r.URL.Query().Get("ifGenerationMatch") != "0" {
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+		return
+	}
+	reader := multipart.NewReader(r.Body, params["boundary"])
+	parts := [][]byte{}
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			break
+		}
+		b, _ := ioutil.ReadAll(part)
+		parts = append(parts, b)
+	}
+	object := gcsObject{}
+	if len(parts) != 2 || json.Unmarshal(parts[0], &object) != nil {
+		http.Error(w, "unexpected body", http.StatusBadRequest)
+		return
+	}
+	if _, ok := f.objects[object.Name]; ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusPreconditionFailed)
+		w.Write([]byte(`{"error":{"code":412,"message":"conditionNotMet"}}`))
+		return
+	}
+	f.objects[object.Name] = parts[1]
+	f.metadata[object.Name] = object.Metadata
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(object)
+}
+
+// list answers the objects one at a time, to go through the pages
+func (f *fakeGCS) list(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	names := []string{}
+	for name := range f.objects {
+		if !strings.HasPrefix(name, query.Get("prefix")) || name <= query.Get("pageToken") || name < query.Get("startOffset") {
+			continue
+		}
+		if end := query.Get("endOffset"); end != "" && name >= end {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	page := struct {
+		Items         []gcsObject `json:"items,omitempty"`
+		NextPageToken string      `json:"nextPageToken,omitempty"`
+	}{}
+	if len(names) > 0 {
+		page.Items = []gcsObject{{Name: names[0], Metadata: f.metadata[names[0]]}}
+		if len(names) > 1 {
+			page.NextPageToken = names[0]
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+func newTestGCSStore(t *testing.T, server *httptest.Server) *GCSStore {
+	t.Helper()
+	client := httpService.NewService(server.Client(), httpService.WithRetryPolicy(httpService.NoRetry))
+	s, err := newGCSStore(context.Background(), client, server.URL, "audit", "consent-audit/")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	return s
+}
+
+func TestGCSStoreChain(t *testing.T) {
+	f, server := newFakeGCS(t)
+	s := newTestGCSStore(t, server)
+	records := appendRecords(t, s, "example.com", "example.ca")
+
+	if _, ok := f.objects["consent-audit/records/00000000000000000001.json"]; !ok {
+		t.Errorf("got objects %v, want the first record at position 1", f.metadata)
+	}
+	if records[1].PrevHash != records[0].Hash {
+		t.Errorf("record %s is not chained to the previous one", records[1].ID)
+	}
+	if err := s.Verify(context.Background()); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	// a store opened afterwards goes on with the chain
+	next := appendRecords(t, newTestGCSStore(t, server), "example.org")[0]
+	if next.PrevHash != records[1].Hash {
+		t.Errorf("got previous hash %s, want %s", next.PrevHash, records[1].Hash)
+	}
+}
+
+func TestGCSStoreConcurrentReplicas(t *testing.T) {
+	_, server := newFakeGCS(t)
+	a := newTestGCSStore(t, server)
+	b := newTestGCSStore(t, server)
+
+	appendRecords(t, a, "example.com")
+	// b doesn't know of the record of a, its append conflicts and then goes after it
+	appendRecords(t, b, "example.ca")
+	appendRecords(t, a, "example.org")
+
+	records, err := a.Query(context.Background(), Filter{})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	got := []string{}
+	for _, r := range records {
+		got = append(got, r.Domain)
+	}
+	if strings.Join(got, ",") != "example.com,example.ca,example.org" {
+		t.Errorf("got %v, want every record in the order appended", got)
+	}
+	if err := b.Verify(context.Background()); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestGCSStoreVerifyTamperedRecord(t *testing.T) {
+	f, server := newFakeGCS(t)
+	s := newTestGCSStore(t, server)
+	appendRecords(t, s, "example.com", "example.ca")
+
+	name := "consent-audit/records/00000000000000000001.json"
+	f.objects[name] = []byte(strings.Replace(string(f.objects[name]), "203.0.113.7", "198.51.100.1", 1))
+	if err := s.Verify(context.Background()); err == nil {
+		t.Error("expected the tampered record to be detected")
+	}
+
+	delete(f.objects, name)
+	if err := s.Verify(context.Background()); err == nil {
+		t.Error("expected the removed record to be detected")
+	}
+}
+
+func TestGCSStoreQuery(t *testing.T) {
+	_, server := newFakeGCS(t)
+	s := newTestGCSStore(t, server)
+	records := appendRecords(t, s, "example.com", "example.ca", "example.com")
+	tenantRecord, err := s.Append(context.Background(), Record{Kind: KindConsent, Domain: "example.org", Tenant: "acme"})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	cases := []struct {
+		name   string
+		filter Filter
+		want   []Record
+	}{
+		{name: "all", filter: Filter{}, want: append(records, tenantRecord)},
+		{name: "domain", filter: Filter{Domain: "example.com"}, want: []Record{records[0], records[2]}},
+		{name: "tenant", filter: Filter{Tenant: "acme"}, want: []Record{tenantRecord}},
+		{name: "other tenant", filter: Filter{Tenant: "globex"}, want: []Record{}},
+		{name: "from is inclusive", filter: Filter{From: records[1].RecordedAt, To: tenantRecord.RecordedAt}, want: records[1:]},
+		{name: "to is exclusive", filter: Filter{To: records[1].RecordedAt}, want: records[:1]},
+		{name: "empty range", filter: Filter{From: records[1].RecordedAt, To: records[1].RecordedAt}, want: []Record{}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := s.Query(context.Background(), c.filter)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if len(got) != len(c.want) {
+				t.Fatalf("got %d records, want %d", len(got), len(c.want))
+			}
+			for i := range got {
+				if got[i].ID != c.want[i].ID || got[i].Hash != c.want[i].Hash {
+					t.Errorf("record %d: got %s, want %s", i, got[i].ID, c.want[i].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestGCSStoreOnlyDownloadsSelectedRecords(t *testing.T) {
+	f, server := newFakeGCS(t)
+	s := newTestGCSStore(t, server)
+	domains := make([]string, 40)
+	for i := range domains {
+		domains[i] = "example.com"
+	}
+	domains[25] = "example.ca"
+	appendRecords(t, s, domains...)
+
+	f.lists, f.downloads = 0, 0
+	reopened := newTestGCSStore(t, server)
+	if f.lists != 0 || f.downloads > 14 {
+		t.Errorf("got %d lists and %d downloads to open the store, want the end of the chain found by bisection", f.lists, f.downloads)
+	}
+	if reopened.lastSeq != 40 {
+		t.Errorf("got the end of the chain at %d, want 40", reopened.lastSeq)
+	}
+
+	f.downloads = 0
+	got, err := reopened.Query(context.Background(), Filter{Domain: "example.ca"})
+	if err != nil || len(got) != 1 {
+		t.Fatalf("got %v, %v, want the record of example.ca", got, err)
+	}
+	// one download for catching up with the end of the chain, one for the record selected
+	if f.downloads != 2 {
+		t.Errorf("got %d downloads, want only the record selected", f.downloads)
+	}
+}
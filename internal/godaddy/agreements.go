@@ -0,0 +1,123 @@
+package godaddy
+
+import (
+	"context"
+	httpService "github.com/glucn/godaddy/internal/http"
+	"github.com/glucn/godaddy/internal/redact"
+	"github.com/vendasta/gosdks/util"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const getPurchaseAgreementPath = "/domains/agreements"
+
+// Agreement is a legal agreement the registrant must accept to register or transfer a domain
+type Agreement struct {
+	Key     string `json:"agreementKey"`
+	Title   string `json:"title"`
+	URL     string `json:"url,omitempty"`
+	Content string `json:"content,omitempty"`
+}
+
+// AgreementOptions selects the variant of the agreements
+type AgreementOptions struct {
+	// Privacy includes the agreements of the privacy service, required when purchasing with privacy
+	Privacy bool
+	// ForTransfer returns the agreements of a transfer instead of a registration
+	ForTransfer bool
+}
+
+func (s *Service) GetPurchaseAgreement(ctx context.Context, tld string, opts AgreementOptions) ([]Agreement, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Catalog)
+	defer cancel()
+
+	param := []httpService.URLParam{
+		{
+			Key:   "tlds",
+			Value: tld,
+		},
+		{
+			Key:   "privacy",
+			Value: strconv.FormatBool(opts.Privacy),
+		},
+		{
+			Key:   "forTransfer",
+			Value: strconv.FormatBool(opts.ForTransfer),
+		},
+	}
+	url := s.endpoint.url(getPurchaseAgreementPath)
+	res, err := s.call(ctx, http.MethodGet, url, nil, "", param)
+
+	if err != nil {
+		redact.Errorf(ctx, "Error calling %s: %s", url, err.Error())
+		return nil, convertError(err, "Error getting purchase agreement")
+	}
+
+	body := make([]Agreement, 0)
+	if err := httpService.DecodeJSON(res, &body); err != nil {
+		redact.Errorf(ctx, "Error decoding response body of %s: %v", res.Request.URL, err)
+		return nil, err
+	}
+	return body, nil
+}
+
+// BuildConsent returns the consent of the end-user at agreedBy to the agreements of the TLD of domain. It fails
+// unless agreementKeys are exactly the keys of the agreements GoDaddy requires.
+func (s *Service) BuildConsent(ctx context.Context, domain string, opts AgreementOptions, agreementKeys []string, agreedBy string) (Consent, error) {
+	if err := validateDomain(domain); err != nil {
+		return Consent{}, err
+	}
+	required, err := s.GetPurchaseAgreement(ctx, tldOf(domain), opts)
+	if err != nil {
+		return Consent{}, err
+	}
+	return NewConsent(required, agreementKeys, agreedBy)
+}
+
+// NewConsent returns the consent, given now by the end-user at IP address agreedBy, to the required agreements.
+// The agreement keys must match the keys of the required agreements, not one more or less.
+func NewConsent(required []Agreement, agreementKeys []string, agreedBy string) (Consent, error) {
+	if net.ParseIP(agreedBy) == nil {
+		return Consent{}, util.Error(util.InvalidArgument, "AgreedBy must be the IP address of the end-user, got %q", agreedBy)
+	}
+
+	accepted := make(map[string]bool, len(agreementKeys))
+	for _, k := range agreementKeys {
+		accepted[k] = true
+	}
+	expected := make(map[string]bool, len(required))
+	missing := []string{}
+	for _, a := range required {
+		expected[a.Key] = true
+		if !accepted[a.Key] {
+			missing = append(missing, a.Key)
+		}
+	}
+	unexpected := []string{}
+	for k := range accepted {
+		if !expected[k] {
+			unexpected = append(unexpected, k)
+		}
+	}
+	if len(missing) > 0 {
+		return Consent{}, util.Error(util.FailedPrecondition, "Consent to agreements %s is required", strings.Join(missing, ", "))
+	}
+	if len(unexpected) > 0 {
+		sort.Strings(unexpected)
+		return Consent{}, util.Error(util.InvalidArgument, "Agreements %s are not required for this domain", strings.Join(unexpected, ", "))
+	}
+
+	keys := make([]string, 0, len(required))
+	for _, a := range required {
+		keys = append(keys, a.Key)
+	}
+	return Consent{
+		AgreedAt:      time.Now().UTC().Format(time.RFC3339),
+		AgreedBy:      agreedBy,
+		AgreementKeys: keys,
+	}, nil
+}
@@ -0,0 +1,97 @@
+package godaddy
+
+import (
+	"context"
+	"github.com/vendasta/gosdks/util"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var testAgreements = []Agreement{
+	{Key: "DNRA", Title: "Domain Name Registration Agreement"},
+	{Key: "DNPA", Title: "Domains by Proxy Agreement"},
+}
+
+func TestNewConsent(t *testing.T) {
+	cases := []struct {
+		name      string
+		keys      []string
+		agreedBy  string
+		errorType util.ErrorType
+	}{
+		{name: "every agreement", keys: []string{"DNPA", "DNRA"}, agreedBy: "203.0.113.7"},
+		{name: "ipv6", keys: []string{"DNRA", "DNPA"}, agreedBy: "2001:db8::7"},
+		{name: "missing agreement", keys: []string{"DNRA"}, agreedBy: "203.0.113.7", errorType: util.FailedPrecondition},
+		{name: "no agreement", agreedBy: "203.0.113.7", errorType: util.FailedPrecondition},
+		{name: "unexpected agreement", keys: []string{"DNRA", "DNPA", "DNTA"}, agreedBy: "203.0.113.7", errorType: util.InvalidArgument},
+		{name: "agreedBy is not an ip", keys: []string{"DNRA", "DNPA"}, agreedBy: "jane", errorType: util.InvalidArgument},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			consent, err := NewConsent(testAgreements, c.keys, c.agreedBy)
+			if c.errorType != 0 {
+				if util.FromError(err).ErrorType() != c.errorType {
+					t.Fatalf("got error %v, want %s", err, c.errorType)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if want := []string{"DNRA", "DNPA"}; !reflect.DeepEqual(consent.AgreementKeys, want) {
+				t.Errorf("got keys %v, want the keys of the required agreements %v", consent.AgreementKeys, want)
+			}
+			if consent.AgreedBy != c.agreedBy {
+				t.Errorf("got agreedBy %q, want %q", consent.AgreedBy, c.agreedBy)
+			}
+		})
+	}
+}
+
+func TestNewConsentAgreedAt(t *testing.T) {
+	before := time.Now().Add(-time.Second)
+	consent, err := NewConsent(testAgreements, []string{"DNRA", "DNPA"}, "203.0.113.7")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	agreedAt, err := time.Parse(time.RFC3339, consent.AgreedAt)
+	if err != nil {
+		t.Fatalf("got agreedAt %q, want an RFC 3339 timestamp: %v", consent.AgreedAt, err)
+	}
+	if consent.AgreedAt[len(consent.AgreedAt)-1] != 'Z' {
+		t.Errorf("got agreedAt %q, want it in UTC", consent.AgreedAt)
+	}
+	if agreedAt.Before(before) || agreedAt.After(time.Now()) {
+		t.Errorf("got agreedAt %s, want now", agreedAt)
+	}
+}
+
+func TestBuildConsent(t *testing.T) {
+	var query map[string]string
+	s := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = map[string]string{}
+		for key := range r.URL.Query() {
+			query[key] = r.URL.Query().Get(key)
+		}
+		serveJSON(http.StatusOK, `[{"agreementKey":"DNTA","title":"Domain Name Transfer Agreement"}]`)(w, r)
+	}))
+
+	consent, err := s.BuildConsent(context.Background(), "example.co.uk", AgreementOptions{ForTransfer: true}, []string{"DNTA"}, "203.0.113.7")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if want := map[string]string{"tlds": "co.uk", "privacy": "false", "forTransfer": "true"}; !reflect.DeepEqual(query, want) {
+		t.Errorf("got query %v, want %v", query, want)
+	}
+	if !reflect.DeepEqual(consent.AgreementKeys, []string{"DNTA"}) {
+		t.Errorf("got keys %v, want DNTA", consent.AgreementKeys)
+	}
+
+	_, err = s.BuildConsent(context.Background(), "example.co.uk", AgreementOptions{}, []string{"DNRA"}, "203.0.113.7")
+	if util.FromError(err).ErrorType() != util.FailedPrecondition {
+		t.Errorf("got error %v, want FailedPrecondition for keys other than the required ones", err)
+	}
+}
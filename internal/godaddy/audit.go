@@ -0,0 +1,77 @@
+package godaddy
+
+import (
+	"context"
+	"github.com/glucn/godaddy/internal/audit"
+	"github.com/glucn/godaddy/internal/redact"
+	"github.com/vendasta/gosdks/util"
+)
+
+// auditedService records the consent given to every purchase, see NewAuditedService
+type auditedService struct {
+	Interface
+	store audit.Store
+}
+
+// NewAuditedService returns a service appending an audit record of the consent to store before every PurchaseDomain
+// call, and a record of its outcome after it, including the failed ones. No purchase is made when the consent can't
+// be recorded. The other calls are left to inner.
+func NewAuditedService(inner Interface, store audit.Store) Interface {
+	return &auditedService{Interface: inner, store: store}
+}
+
+func (s *auditedService) PurchaseDomain(ctx context.Context, domain string, contacts DomainContacts, consent Consent, opts PurchaseOptions) (PurchaseResult, error) {
+	// no purchase is made unless the agreements consented to are known, otherwise the consent could not be proven
+	agreements, err := s.Interface.GetPurchaseAgreement(ctx, tldOf(domain), AgreementOptions{Privacy: opts.Privacy})
+	if err != nil {
+		return PurchaseResult{}, err
+	}
+
+	record := audit.Record{
+		Kind:            audit.KindConsent,
+		Domain:          domain,
+		AgreementKeys:   consent.AgreementKeys,
+		AgreementHashes: make(map[string]string, len(consent.AgreementKeys)),
+		AgreedBy:        consent.AgreedBy,
+		AgreedAt:        consent.AgreedAt,
+		Actor:           audit.ActorFromContext(ctx),
+	}
+	for _, a := range agreements {
+		for _, k := range consent.AgreementKeys {
+			if a.Key == k {
+				record.AgreementHashes[k] = audit.HashContent(a.Content)
+			}
+		}
+	}
+	if tenant, ok := TenantFromContext(ctx); ok {
+		record.Tenant = tenant.ID
+	}
+
+	// the consent is recorded before the order, a purchase without proof of consent must not be made
+	consentRecord, err := s.store.Append(ctx, record)
+	if err != nil {
+		redact.Errorf(ctx, "Error recording consent to the purchase of domain %s: %s", domain, err.Error())
+		return PurchaseResult{}, util.Error(util.Unavailable, "Error recording consent, domain %s was not purchased", domain)
+	}
+
+	result, err := s.Interface.PurchaseDomain(ctx, domain, contacts, consent, opts)
+	outcome := audit.Record{
+		Kind:      audit.KindOutcome,
+		Domain:    domain,
+		Actor:     record.Actor,
+		Tenant:    record.Tenant,
+		ConsentID: consentRecord.ID,
+	}
+	if err != nil {
+		outcome.Error = err.Error()
+	} else {
+		outcome.OrderID = result.OrderID
+	}
+
+	// the purchase can't be undone, so a failure to record its outcome is reported without hiding the order from the
+	// caller, the consent record is enough to reconcile it with the orders of GoDaddy
+	if _, auditErr := s.store.Append(ctx, outcome); auditErr != nil {
+		redact.Errorf(ctx, "Error recording the outcome of the purchase of domain %s, consent %s, order %d: %s", domain, consentRecord.ID, outcome.OrderID, auditErr.Error())
+	}
+	return result, err
+}
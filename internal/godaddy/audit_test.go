@@ -0,0 +1,121 @@
+package godaddy
+
+import (
+	"context"
+	"github.com/glucn/godaddy/internal/audit"
+	"github.com/vendasta/gosdks/util"
+	"testing"
+)
+
+// fakeStore keeps the records appended in memory, failing every append after the first failAfter ones
+type fakeStore struct {
+	records   []audit.Record
+	failAfter int
+}
+
+func (s *fakeStore) Append(ctx context.Context, r audit.Record) (audit.Record, error) {
+	if len(s.records) >= s.failAfter {
+		return audit.Record{}, util.Error(util.Unavailable, "storage is down")
+	}
+	r.ID = string(r.Kind) + "-" + r.Domain
+	s.records = append(s.records, r)
+	return r, nil
+}
+
+func (s *fakeStore) Query(ctx context.Context, f audit.Filter) ([]audit.Record, error) {
+	return s.records, nil
+}
+
+// fakePurchaser answers agreements and purchases, recording how many purchases were made
+type fakePurchaser struct {
+	Interface
+	store     *fakeStore
+	purchases int
+	// recordsAtPurchase is how many records were appended when the order was placed
+	recordsAtPurchase int
+	err               error
+}
+
+func (p *fakePurchaser) GetPurchaseAgreement(ctx context.Context, tld string, opts AgreementOptions) ([]Agreement, error) {
+	return []Agreement{{Key: "DNRA", Content: "<p>Registration agreement</p>"}}, nil
+}
+
+func (p *fakePurchaser) PurchaseDomain(ctx context.Context, domain string, contacts DomainContacts, consent Consent, opts PurchaseOptions) (PurchaseResult, error) {
+	p.purchases++
+	p.recordsAtPurchase = len(p.store.records)
+	if p.err != nil {
+		return PurchaseResult{}, p.err
+	}
+	return PurchaseResult{Domain: domain, OrderResult: OrderResult{OrderID: 42}}, nil
+}
+
+var testConsent = Consent{AgreedAt: "2026-10-17T12:00:00Z", AgreedBy: "203.0.113.7", AgreementKeys: []string{"DNRA"}}
+
+func TestAuditedPurchaseRecordsConsentBeforeOrder(t *testing.T) {
+	store := &fakeStore{failAfter: 10}
+	inner := &fakePurchaser{store: store}
+	s := NewAuditedService(inner, store)
+
+	ctx := audit.WithActor(context.Background(), "acme-backend/jane")
+	result, err := s.PurchaseDomain(ctx, "example.com", DomainContacts{}, testConsent, PurchaseOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if result.OrderID != 42 {
+		t.Errorf("got order %d, want 42", result.OrderID)
+	}
+	if inner.recordsAtPurchase != 1 {
+		t.Errorf("got %d records when the order was placed, want the consent to be recorded first", inner.recordsAtPurchase)
+	}
+	if len(store.records) != 2 {
+		t.Fatalf("got %d records, want the consent and the outcome", len(store.records))
+	}
+
+	consent, outcome := store.records[0], store.records[1]
+	if consent.Kind != audit.KindConsent || consent.AgreedBy != "203.0.113.7" || consent.Actor != "acme-backend/jane" {
+		t.Errorf("got consent record %+v", consent)
+	}
+	if consent.AgreementHashes["DNRA"] != audit.HashContent("<p>Registration agreement</p>") {
+		t.Errorf("got agreement hashes %v, want the hash of the content agreed to", consent.AgreementHashes)
+	}
+	if outcome.Kind != audit.KindOutcome || outcome.ConsentID != consent.ID || outcome.OrderID != 42 {
+		t.Errorf("got outcome record %+v", outcome)
+	}
+}
+
+func TestAuditedPurchaseRefusedWithoutConsentRecord(t *testing.T) {
+	store := &fakeStore{failAfter: 0}
+	inner := &fakePurchaser{store: store}
+	s := NewAuditedService(inner, store)
+
+	_, err := s.PurchaseDomain(context.Background(), "example.com", DomainContacts{}, testConsent, PurchaseOptions{})
+	if got := util.FromError(err).ErrorType(); got != util.Unavailable {
+		t.Errorf("got %v, want Unavailable", err)
+	}
+	if inner.purchases != 0 {
+		t.Errorf("got %d purchases, want none when the consent can't be recorded", inner.purchases)
+	}
+}
+
+func TestAuditedPurchaseRecordsFailure(t *testing.T) {
+	store := &fakeStore{failAfter: 10}
+	inner := &fakePurchaser{store: store, err: &APIError{StatusCode: 422, Code: "UNAVAILABLE_DOMAIN", Message: "Domain isn't available"}}
+	s := NewAuditedService(inner, store)
+
+	if _, err := s.PurchaseDomain(context.Background(), "example.com", DomainContacts{}, testConsent, PurchaseOptions{}); err == nil {
+		t.Fatal("expected the purchase error")
+	}
+	if len(store.records) != 2 || store.records[1].Error == "" || store.records[1].OrderID != 0 {
+		t.Errorf("got records %+v, want the failure to be recorded", store.records)
+	}
+}
+
+func TestAuditedPurchaseKeepsOrderWhenOutcomeIsNotRecorded(t *testing.T) {
+	store := &fakeStore{failAfter: 1}
+	s := NewAuditedService(&fakePurchaser{store: store}, store)
+
+	result, err := s.PurchaseDomain(context.Background(), "example.com", DomainContacts{}, testConsent, PurchaseOptions{})
+	if err != nil || result.OrderID != 42 {
+		t.Errorf("got %+v, %v, want the order placed to be answered", result, err)
+	}
+}
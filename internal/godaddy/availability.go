@@ -0,0 +1,182 @@
+package godaddy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	httpService "github.com/glucn/godaddy/internal/http"
+	"github.com/glucn/godaddy/internal/redact"
+	"github.com/vendasta/gosdks/util"
+	"github.com/vendasta/gosdks/validation"
+	"net/http"
+	"strings"
+)
+
+// MaxAvailabilityBatch is the number of domains GoDaddy checks in one bulk availability call
+const MaxAvailabilityBatch = 500
+
+// CheckType trades the speed of an availability check for its accuracy
+type CheckType string
+
+const (
+	// CheckTypeFast answers from cached data, results may not be definitive
+	CheckTypeFast CheckType = "FAST"
+	// CheckTypeFull asks the registries, results are definitive
+	CheckTypeFull CheckType = "FULL"
+)
+
+// AvailabilityResult is the availability and price of a domain
+type AvailabilityResult struct {
+	Domain    string `json:"domain"`
+	Available bool   `json:"available"`
+	// Definitive is false when the availability comes from cached data and may be stale
+	Definitive bool `json:"definitive"`
+	// Price is the price of registering the domain for Period years
+	Price  Money `json:"price"`
+	Period int   `json:"period"`
+}
+
+// availabilityResponse is the availability of a domain as returned by GoDaddy
+type availabilityResponse struct {
+	Domain     string `json:"domain"`
+	Available  bool   `json:"available"`
+	Definitive bool   `json:"definitive"`
+	Price      int64  `json:"price"`
+	Currency   string `json:"currency"`
+	Period     int    `json:"period"`
+}
+
+func (a availabilityResponse) result() AvailabilityResult {
+	return AvailabilityResult{
+		Domain:     a.Domain,
+		Available:  a.Available,
+		Definitive: a.Definitive,
+		Price:      NewMoney(a.Currency, a.Price),
+		Period:     a.Period,
+	}
+}
+
+// AvailabilityError is why the availability of a single domain of a bulk check is unknown
+type AvailabilityError struct {
+	Domain  string `json:"domain"`
+	Code    string `json:"code"`
+	Message string `json:"message,omitempty"`
+	Status  int    `json:"status,omitempty"`
+}
+
+// BulkAvailability is the outcome of a bulk availability check, every domain checked is in Domains or in Errors
+type BulkAvailability struct {
+	Domains []AvailabilityResult `json:"domains"`
+	Errors  []AvailabilityError  `json:"errors,omitempty"`
+}
+
+// GetDomainsAvailability checks the availability of many domains, in as few calls as GoDaddy allows. Duplicated
+// domains are checked once.
+func (s *Service) GetDomainsAvailability(ctx context.Context, domains []string, checkType CheckType) (BulkAvailability, error) {
+	if checkType == "" {
+		checkType = CheckTypeFast
+	}
+	err := validation.NewValidator().
+		Rule(validation.AtLeastOneStringRequired(domains, util.InvalidArgument, "At least one domain is required")).
+		Rule(validation.StringInSlice(string(checkType), []string{string(CheckTypeFast), string(CheckTypeFull)}, util.InvalidArgument, "Check type must be FAST or FULL")).
+		Validate()
+	if err != nil {
+		return BulkAvailability{}, err
+	}
+
+	seen := make(map[string]bool, len(domains))
+	unique := make([]string, 0, len(domains))
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if d == "" || seen[d] {
+			continue
+		}
+		seen[d] = true
+		unique = append(unique, d)
+	}
+
+	result := BulkAvailability{Domains: make([]AvailabilityResult, 0, len(unique))}
+	for start := 0; start < len(unique); start += MaxAvailabilityBatch {
+		end := start + MaxAvailabilityBatch
+		if end > len(unique) {
+			end = len(unique)
+		}
+		batch, err := s.getDomainsAvailability(ctx, unique[start:end], checkType)
+		if err != nil {
+			return BulkAvailability{}, err
+		}
+		result.Domains = append(result.Domains, batch.Domains...)
+		result.Errors = append(result.Errors, batch.Errors...)
+	}
+	return result, nil
+}
+
+// getDomainsAvailability checks a batch of at most MaxAvailabilityBatch domains
+func (s *Service) getDomainsAvailability(ctx context.Context, domains []string, checkType CheckType) (BulkAvailability, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Availability)
+	defer cancel()
+
+	body := new(bytes.Buffer)
+	if err := json.NewEncoder(body).Encode(domains); err != nil {
+		redact.Errorf(ctx, "Error encoding availability body: %v", err)
+		return BulkAvailability{}, util.Error(util.Internal, "Error getting domains availability")
+	}
+
+	param := []httpService.URLParam{
+		{
+			Key:   "checkType",
+			Value: string(checkType),
+		},
+	}
+
+	// checking availability has no side effect, it is safe to retry despite being a POST
+	url := s.endpoint.url(domainsAvailablePath)
+	res, err := s.call(httpService.WithRetryNonIdempotent(ctx), http.MethodPost, url, body, httpService.ContentTypeJSON, param)
+	if err != nil {
+		redact.Errorf(ctx, "Error calling %s: %s", url, err.Error())
+		return BulkAvailability{}, convertError(err, "Error getting domains availability")
+	}
+
+	type response struct {
+		Domains []availabilityResponse `json:"domains"`
+		Errors  []AvailabilityError    `json:"errors"`
+	}
+
+	decoded := &response{}
+	if err := httpService.DecodeJSON(res, decoded); err != nil {
+		redact.Errorf(ctx, "Error decoding response body of %s: %v", res.Request.URL, err)
+		return BulkAvailability{}, err
+	}
+
+	result := BulkAvailability{Domains: make([]AvailabilityResult, len(decoded.Domains)), Errors: decoded.Errors}
+	for i, d := range decoded.Domains {
+		result.Domains[i] = d.result()
+	}
+	return result, nil
+}
+
+func (s *Service) GetDomainAvailability(ctx context.Context, domain string) (AvailabilityResult, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Availability)
+	defer cancel()
+
+	param := []httpService.URLParam{
+		{
+			Key:   "domain",
+			Value: domain,
+		},
+	}
+	res, err := s.call(ctx, http.MethodGet, s.endpoint.url(domainsAvailablePath), nil, "", param)
+
+	if err != nil {
+		redact.Errorf(ctx, "Error calling /v1/domains/available: %s", err.Error())
+		return AvailabilityResult{}, convertError(err, "Error getting domain availability")
+	}
+
+	body := &availabilityResponse{}
+	if err := httpService.DecodeJSON(res, body); err != nil {
+		redact.Errorf(ctx, "Error decoding response body of %s: %v", res.Request.URL, err)
+		return AvailabilityResult{}, err
+	}
+
+	return body.result(), nil
+}
@@ -0,0 +1,140 @@
+package godaddy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/vendasta/gosdks/util"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeBulkAvailability answers the bulk availability calls, every domain available except those under .invalid
+// which are reported as errors. It fails the call holding failDomain.
+type fakeBulkAvailability struct {
+	mu         sync.Mutex
+	batches    [][]string
+	failDomain string
+}
+
+func (f *fakeBulkAvailability) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	domains := []string{}
+	if err := json.NewDecoder(r.Body).Decode(&domains); err != nil || r.URL.Path != "/v1/domains/available" || r.URL.Query().Get("checkType") != "FULL" {
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+		return
+	}
+	f.mu.Lock()
+	f.batches = append(f.batches, domains)
+	f.mu.Unlock()
+
+	type response struct {
+		Domains []availabilityResponse `json:"domains"`
+		Errors  []AvailabilityError    `json:"errors,omitempty"`
+	}
+	res := response{Domains: []availabilityResponse{}}
+	for _, d := range domains {
+		if d == f.failDomain {
+			serveJSON(http.StatusInternalServerError, `{"code":"INTERNAL_SERVER_ERROR","message":"Internal error"}`)(w, r)
+			return
+		}
+		if strings.HasSuffix(d, ".invalid") {
+			res.Errors = append(res.Errors, AvailabilityError{Domain: d, Code: "UNSUPPORTED_TLD", Status: 422})
+			continue
+		}
+		res.Domains = append(res.Domains, availabilityResponse{Domain: d, Available: true, Definitive: true, Price: 11990000, Currency: "USD", Period: 1})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// bulkDomains returns n distinct domains, the last one under .invalid
+func bulkDomains(n int) []string {
+	domains := make([]string, n)
+	for i := range domains {
+		domains[i] = fmt.Sprintf("example-%d.com", i)
+	}
+	domains[n-1] = "example.invalid"
+	return domains
+}
+
+func TestGetDomainsAvailabilityBatches(t *testing.T) {
+	cases := []struct {
+		name    string
+		domains int
+		batches []int
+	}{
+		{name: "one batch", domains: 3, batches: []int{3}},
+		{name: "full batch", domains: MaxAvailabilityBatch, batches: []int{MaxAvailabilityBatch}},
+		{name: "one more than a batch", domains: MaxAvailabilityBatch + 1, batches: []int{MaxAvailabilityBatch, 1}},
+		{name: "several batches", domains: 2*MaxAvailabilityBatch + 10, batches: []int{MaxAvailabilityBatch, MaxAvailabilityBatch, 10}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fake := &fakeBulkAvailability{}
+			s := newTestService(t, fake)
+			domains := bulkDomains(c.domains)
+			// duplicates, in another case and with spaces, are checked once
+			withDuplicates := append(append([]string{}, domains...), strings.ToUpper(domains[0]), " "+domains[1]+" ", "")
+
+			result, err := s.GetDomainsAvailability(context.Background(), withDuplicates, CheckTypeFull)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if len(fake.batches) != len(c.batches) {
+				t.Fatalf("got %d calls, want %d", len(fake.batches), len(c.batches))
+			}
+			for i, batch := range fake.batches {
+				if len(batch) != c.batches[i] {
+					t.Errorf("call %d: got %d domains, want %d", i, len(batch), c.batches[i])
+				}
+			}
+
+			// every domain is answered once, in Domains or in Errors
+			answered := map[string]int{}
+			for _, d := range result.Domains {
+				answered[d.Domain]++
+				if d.Price != NewMoney("USD", 11990000) {
+					t.Errorf("got price %+v for %s", d.Price, d.Domain)
+				}
+			}
+			for _, e := range result.Errors {
+				answered[e.Domain]++
+			}
+			if len(answered) != c.domains || len(result.Domains)+len(result.Errors) != c.domains {
+				t.Errorf("got %d domains answered, want %d", len(answered), c.domains)
+			}
+			if len(result.Errors) != 1 || result.Errors[0].Domain != "example.invalid" {
+				t.Errorf("got errors %+v, want the error of the last batch", result.Errors)
+			}
+		})
+	}
+}
+
+func TestGetDomainsAvailabilityFailedBatch(t *testing.T) {
+	domains := bulkDomains(MaxAvailabilityBatch + 1)
+	fake := &fakeBulkAvailability{failDomain: domains[MaxAvailabilityBatch]}
+	s := newTestService(t, fake)
+
+	// a failed batch fails the whole check, a partial result would read as if the other domains weren't checked
+	result, err := s.GetDomainsAvailability(context.Background(), domains, CheckTypeFull)
+	if util.FromError(err).ErrorType() != util.Unavailable {
+		t.Errorf("got %v, want Unavailable", err)
+	}
+	if len(result.Domains) != 0 || len(fake.batches) != 2 {
+		t.Errorf("got %d domains after %d calls, want no result after the second call", len(result.Domains), len(fake.batches))
+	}
+}
+
+func TestGetDomainsAvailabilityValidation(t *testing.T) {
+	s := newTestService(t, http.NotFoundHandler())
+	for name, domains := range map[string][]string{"no domain": nil, "empty domains": {"", ""}} {
+		if _, err := s.GetDomainsAvailability(context.Background(), domains, CheckTypeFast); util.FromError(err).ErrorType() != util.InvalidArgument {
+			t.Errorf("%s: got %v, want InvalidArgument", name, err)
+		}
+	}
+	if _, err := s.GetDomainsAvailability(context.Background(), []string{"example.com"}, "SLOW"); util.FromError(err).ErrorType() != util.InvalidArgument {
+		t.Errorf("got %v, want InvalidArgument for an unknown check type", err)
+	}
+}
@@ -0,0 +1,383 @@
+package godaddy
+
+import (
+	"context"
+	"github.com/glucn/godaddy/internal/redact"
+	"github.com/vendasta/gosdks/util"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultCatalogPace is the default delay between loading the details of two TLDs. A TLD takes 3 calls, so the
+	// catalog uses less than a third of the GoDaddy rate limit and leaves the rest to the end-users.
+	defaultCatalogPace = 10 * time.Second
+
+	// catalogRetryMin and catalogRetryMax bound the delay before the TLDs whose details couldn't be loaded are retried
+	catalogRetryMin = time.Minute
+	catalogRetryMax = 30 * time.Minute
+)
+
+// CatalogTLD is a TLD with what is needed to sell domains under it
+type CatalogTLD struct {
+	TLD
+	// AgreementKeys are the keys of the agreements required to register a domain under the TLD, without privacy
+	AgreementKeys []string `json:"agreementKeys"`
+	// PrivacyAgreementKeys are the keys of the agreements required to register a domain under the TLD with privacy
+	PrivacyAgreementKeys []string `json:"privacyAgreementKeys"`
+	// RequiredFields are the fields the purchase schema of the TLD requires
+	RequiredFields []string `json:"requiredFields"`
+	// ExtraFields are the fields specific to the TLD, ex: the nexus of .us
+	ExtraFields []string `json:"extraFields"`
+	// Incomplete tells that the details of the TLD couldn't be loaded yet, only its name and type are known
+	Incomplete bool `json:"incomplete,omitempty"`
+	// Schema is the purchase schema of the TLD, to validate purchases without calling GoDaddy
+	Schema PurchaseSchema `json:"-"`
+
+	// agreements and privacyAgreements are kept without their content, which is only needed to record a consent
+	agreements        []Agreement
+	privacyAgreements []Agreement
+}
+
+// Agreements returns the agreements required to register a domain under the TLD, without their content
+func (t CatalogTLD) Agreements(privacy bool) []Agreement {
+	if privacy {
+		return t.privacyAgreements
+	}
+	return t.agreements
+}
+
+// TLDFilter selects TLDs of the catalog, its zero value selects them all
+type TLDFilter struct {
+	Type TLDType
+	// Query only selects the TLDs containing it, ex: co
+	Query string
+	// AgreementKey only selects the TLDs requiring this agreement without privacy, ex: DNRA
+	AgreementKey string
+}
+
+func (f TLDFilter) matches(t CatalogTLD) bool {
+	if f.Type != "" && f.Type != t.Type {
+		return false
+	}
+	if f.Query != "" && !strings.Contains(t.Name, strings.ToLower(f.Query)) {
+		return false
+	}
+	if f.AgreementKey == "" {
+		return true
+	}
+	for _, k := range t.AgreementKeys {
+		if k == f.AgreementKey {
+			return true
+		}
+	}
+	return false
+}
+
+// TLDCatalog keeps the TLDs sold by GoDaddy in memory, so that looking them up makes no call
+type TLDCatalog struct {
+	service Interface
+	only    map[string]bool
+	pace    time.Duration
+
+	// refreshMu makes refreshes and retries run one at a time
+	refreshMu sync.Mutex
+
+	mu       sync.RWMutex
+	tlds     map[string]CatalogTLD
+	loadedAt time.Time
+}
+
+// CatalogOption configures a TLDCatalog
+type CatalogOption func(*TLDCatalog)
+
+// WithCatalogTLDs restricts the catalog to the given TLDs, ex: the ones we actually sell. Loading the details of
+// every TLD GoDaddy supports takes hundreds of calls.
+func WithCatalogTLDs(tlds ...string) CatalogOption {
+	return func(c *TLDCatalog) {
+		c.only = make(map[string]bool, len(tlds))
+		for _, t := range tlds {
+			c.only[strings.ToLower(strings.TrimPrefix(t, "."))] = true
+		}
+	}
+}
+
+// WithCatalogPace sets the delay between loading the details of two TLDs, defaultCatalogPace by default
+func WithCatalogPace(pace time.Duration) CatalogOption {
+	return func(c *TLDCatalog) {
+		c.pace = pace
+	}
+}
+
+// NewTLDCatalog returns an empty catalog of the TLDs of service, Refresh loads it
+func NewTLDCatalog(service Interface, opts ...CatalogOption) *TLDCatalog {
+	c := &TLDCatalog{service: service, pace: defaultCatalogPace, tlds: map[string]CatalogTLD{}}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Refresh reloads the catalog. The list of TLDs is published first, with the TLDs not loaded before marked
+// Incomplete, then the details of each TLD are loaded one at a time. The TLDs whose details can't be loaded keep their
+// previous details, or stay Incomplete.
+func (c *TLDCatalog) Refresh(ctx context.Context) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	tlds, err := c.service.ListTLDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	listed := make(map[string]CatalogTLD, len(tlds))
+	for _, t := range tlds {
+		if c.only != nil && !c.only[t.Name] {
+			continue
+		}
+		entry, ok := c.tlds[t.Name]
+		if !ok {
+			entry = CatalogTLD{TLD: t, Incomplete: true}
+		}
+		listed[t.Name] = entry
+	}
+	c.tlds = listed
+	c.loadedAt = time.Now()
+	c.mu.Unlock()
+
+	names := make([]string, 0, len(listed))
+	for name := range listed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if err := c.loadAll(ctx, names); err != nil {
+		return err
+	}
+	redact.Infof(ctx, "Loaded %d TLDs in the catalog, %d incomplete", len(names), len(c.incomplete()))
+	return nil
+}
+
+// retryIncomplete loads the details of the TLDs that are Incomplete
+func (c *TLDCatalog) retryIncomplete(ctx context.Context) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	return c.loadAll(ctx, c.incomplete())
+}
+
+// loadAll loads the details of the TLDs one at a time, pacing the calls so that the rate limit is left to end-users
+func (c *TLDCatalog) loadAll(ctx context.Context, names []string) error {
+	for i, name := range names {
+		if i > 0 && c.pace > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(c.pace):
+			}
+		}
+		if _, err := c.loadAndStore(ctx, name); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			redact.Warningf(ctx, "Error loading details of TLD %s, keeping the previous ones: %s", name, err.Error())
+		}
+	}
+	return nil
+}
+
+// loadAndStore loads the details of a TLD listed in the catalog and stores them, unless the TLD was removed meanwhile
+func (c *TLDCatalog) loadAndStore(ctx context.Context, name string) (CatalogTLD, error) {
+	c.mu.RLock()
+	entry, ok := c.tlds[name]
+	c.mu.RUnlock()
+	if !ok {
+		return CatalogTLD{}, util.Error(util.NotFound, "Domains under the TLD %s are not sold", name)
+	}
+
+	loaded, err := c.load(ctx, entry.TLD)
+	if err != nil {
+		return entry, err
+	}
+	c.mu.Lock()
+	if _, ok := c.tlds[name]; ok {
+		c.tlds[name] = loaded
+	}
+	c.mu.Unlock()
+	return loaded, nil
+}
+
+// load fetches the details of a TLD
+func (c *TLDCatalog) load(ctx context.Context, t TLD) (CatalogTLD, error) {
+	agreements, err := c.service.GetPurchaseAgreement(ctx, t.Name, AgreementOptions{})
+	if err != nil {
+		return CatalogTLD{}, err
+	}
+	privacyAgreements, err := c.service.GetPurchaseAgreement(ctx, t.Name, AgreementOptions{Privacy: true})
+	if err != nil {
+		return CatalogTLD{}, err
+	}
+	schema, err := c.service.GetPurchaseSchema(ctx, t.Name)
+	if err != nil {
+		return CatalogTLD{}, err
+	}
+
+	entry := CatalogTLD{
+		TLD:            t,
+		RequiredFields: schema.Required,
+		ExtraFields:    schema.ExtraFields(),
+		Schema:         schema,
+	}
+	entry.agreements, entry.AgreementKeys = withoutContent(agreements)
+	entry.privacyAgreements, entry.PrivacyAgreementKeys = withoutContent(privacyAgreements)
+	return entry, nil
+}
+
+// withoutContent returns the agreements without their content, and their keys
+func withoutContent(agreements []Agreement) ([]Agreement, []string) {
+	stripped := make([]Agreement, len(agreements))
+	keys := make([]string, len(agreements))
+	for i, a := range agreements {
+		a.Content = ""
+		stripped[i] = a
+		keys[i] = a.Key
+	}
+	return stripped, keys
+}
+
+// incomplete returns the names of the TLDs whose details couldn't be loaded yet, sorted
+func (c *TLDCatalog) incomplete() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	names := []string{}
+	for name, t := range c.tlds {
+		if t.Incomplete {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Watch loads the catalog and refreshes it every interval, until ctx is done. When the TLDs can't be listed, or the
+// details of some can't be loaded, they are retried sooner, backing off from catalogRetryMin up to catalogRetryMax.
+// The catalog is kept as is when a refresh fails.
+func (c *TLDCatalog) Watch(ctx context.Context, interval time.Duration) {
+	c.watch(ctx, interval, catalogRetryMin, catalogRetryMax)
+}
+
+func (c *TLDCatalog) watch(ctx context.Context, interval time.Duration, retryMin time.Duration, retryMax time.Duration) {
+	nextRefresh := time.Now()
+	retry := retryMin
+	for {
+		if !time.Now().Before(nextRefresh) {
+			if err := c.Refresh(ctx); err != nil {
+				redact.Errorf(ctx, "Error refreshing the TLD catalog, keeping the previous one: %s", err.Error())
+			} else {
+				nextRefresh = time.Now().Add(interval)
+			}
+		} else if err := c.retryIncomplete(ctx); err != nil {
+			redact.Errorf(ctx, "Error loading the incomplete TLDs of the catalog: %s", err.Error())
+		}
+
+		// a failed refresh, or the TLDs left incomplete, are retried with backoff until the next regular refresh
+		wait := time.Until(nextRefresh)
+		if wait <= 0 || len(c.incomplete()) > 0 {
+			if wait <= 0 || wait > retry {
+				wait = retry
+			}
+			retry *= 2
+			if retry > retryMax {
+				retry = retryMax
+			}
+		} else {
+			retry = retryMin
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Details returns the TLD with its details, loading them if the TLD is still Incomplete
+func (c *TLDCatalog) Details(ctx context.Context, name string) (CatalogTLD, error) {
+	name = strings.ToLower(strings.TrimPrefix(name, "."))
+	t, ok := c.TLD(name)
+	if !ok {
+		return CatalogTLD{}, util.Error(util.NotFound, "Domains under the TLD %s are not sold", name)
+	}
+	if !t.Incomplete {
+		return t, nil
+	}
+	return c.loadAndStore(ctx, name)
+}
+
+// LoadedAt returns when the TLDs of the catalog were last listed, the zero time if they never were
+func (c *TLDCatalog) LoadedAt() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.loadedAt
+}
+
+// TLD looks up a TLD of the catalog, ex: ca or .ca
+func (c *TLDCatalog) TLD(name string) (CatalogTLD, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	t, ok := c.tlds[strings.ToLower(strings.TrimPrefix(name, "."))]
+	return t, ok
+}
+
+// ForDomain looks up the TLD of a registrable domain, which is everything after its first label: co.uk for
+// example.co.uk, but example.com for www.example.com, so subdomains are not found
+func (c *TLDCatalog) ForDomain(domain string) (CatalogTLD, bool) {
+	return c.TLD(tldOf(domain))
+}
+
+// Sellable tells whether domains can be registered under the TLD
+func (c *TLDCatalog) Sellable(name string) bool {
+	_, ok := c.TLD(name)
+	return ok
+}
+
+// PurchaseSchema returns the purchase schema of the TLD, false if the TLD is not sold or Incomplete
+func (c *TLDCatalog) PurchaseSchema(name string) (PurchaseSchema, bool) {
+	t, ok := c.TLD(name)
+	if !ok || t.Incomplete {
+		return PurchaseSchema{}, false
+	}
+	return t.Schema, true
+}
+
+// RequiredAgreements returns the keys of the agreements required to register a domain under the TLD, with or without
+// privacy. It fails when the TLD is Incomplete.
+func (c *TLDCatalog) RequiredAgreements(name string, privacy bool) ([]string, bool) {
+	t, ok := c.TLD(name)
+	if !ok || t.Incomplete {
+		return nil, false
+	}
+	if privacy {
+		return t.PrivacyAgreementKeys, true
+	}
+	return t.AgreementKeys, true
+}
+
+// List returns the TLDs selected by the filter, sorted by name
+func (c *TLDCatalog) List(filter TLDFilter) []CatalogTLD {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	tlds := make([]CatalogTLD, 0, len(c.tlds))
+	for _, t := range c.tlds {
+		if filter.matches(t) {
+			tlds = append(tlds, t)
+		}
+	}
+	sort.Slice(tlds, func(i, j int) bool { return tlds[i].Name < tlds[j].Name })
+	return tlds
+}
@@ -0,0 +1,257 @@
+package godaddy
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/vendasta/gosdks/util"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeCatalogService answers the TLDs, agreements and schemas of the catalog, failing for the TLDs of failing
+type fakeCatalogService struct {
+	Interface
+
+	mu      sync.Mutex
+	tlds    []TLD
+	listErr error
+	failing map[string]bool
+	calls   int
+}
+
+func (s *fakeCatalogService) ListTLDs(ctx context.Context) ([]TLD, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	return s.tlds, s.listErr
+}
+
+func (s *fakeCatalogService) GetPurchaseAgreement(ctx context.Context, tld string, opts AgreementOptions) ([]Agreement, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.failing[tld] {
+		return nil, util.Error(util.Unavailable, "GoDaddy is down")
+	}
+	agreements := []Agreement{{Key: "DNRA", Title: "Registration agreement", URL: "https://example.com/dnra", Content: "<p>DNRA</p>"}}
+	if opts.Privacy {
+		agreements = append(agreements, Agreement{Key: "DNPA", Title: "Privacy agreement", Content: "<p>DNPA</p>"})
+	}
+	return agreements, nil
+}
+
+func (s *fakeCatalogService) GetPurchaseSchema(ctx context.Context, tld string) (PurchaseSchema, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.failing[tld] {
+		return PurchaseSchema{}, util.Error(util.Unavailable, "GoDaddy is down")
+	}
+	schema := PurchaseSchema{}
+	err := json.Unmarshal([]byte(testSchema), &schema)
+	return schema, err
+}
+
+func (s *fakeCatalogService) fail(tlds ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failing = map[string]bool{}
+	for _, t := range tlds {
+		s.failing[t] = true
+	}
+}
+
+func newFakeCatalogService() *fakeCatalogService {
+	return &fakeCatalogService{tlds: []TLD{
+		{Name: "ca", Type: TLDTypeCountryCode},
+		{Name: "co.uk", Type: TLDTypeCountryCode},
+		{Name: "com", Type: TLDTypeGeneric},
+		{Name: "us", Type: TLDTypeCountryCode},
+	}}
+}
+
+func TestCatalogRefresh(t *testing.T) {
+	service := newFakeCatalogService()
+	c := NewTLDCatalog(service, WithCatalogPace(0))
+	if err := c.Refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	us, ok := c.TLD(".US")
+	if !ok || us.Incomplete {
+		t.Fatalf("got %+v, %t, want the details of us", us, ok)
+	}
+	if len(us.ExtraFields) != 2 || us.Schema.Properties["nexusCategory"].Enum == nil {
+		t.Errorf("got extra fields %v, want the fields of the schema", us.ExtraFields)
+	}
+	if us.Agreements(false)[0].Content != "" {
+		t.Error("got the content of the agreements, want it left out of the catalog")
+	}
+	if _, ok := c.ForDomain("example.co.uk"); !ok {
+		t.Error("expected co.uk to be found for example.co.uk")
+	}
+	if _, ok := c.ForDomain("www.example.com"); ok {
+		t.Error("expected no TLD for a subdomain")
+	}
+	if got := len(c.List(TLDFilter{Type: TLDTypeCountryCode})); got != 3 {
+		t.Errorf("got %d country code TLDs, want 3", got)
+	}
+}
+
+func TestCatalogRequiredAgreements(t *testing.T) {
+	c := NewTLDCatalog(newFakeCatalogService(), WithCatalogPace(0))
+	if err := c.Refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if keys, ok := c.RequiredAgreements("com", false); !ok || len(keys) != 1 || keys[0] != "DNRA" {
+		t.Errorf("got %v, %t, want DNRA without privacy", keys, ok)
+	}
+	if keys, ok := c.RequiredAgreements("com", true); !ok || len(keys) != 2 || keys[1] != "DNPA" {
+		t.Errorf("got %v, %t, want DNRA and DNPA with privacy", keys, ok)
+	}
+	if _, ok := c.RequiredAgreements("org", false); ok {
+		t.Error("expected no agreements for a TLD not sold")
+	}
+}
+
+func TestCatalogKeepsFailedTLDs(t *testing.T) {
+	service := newFakeCatalogService()
+	service.fail("ca")
+	c := NewTLDCatalog(service, WithCatalogPace(0))
+	if err := c.Refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	ca, ok := c.TLD("ca")
+	if !ok || !ca.Incomplete {
+		t.Fatalf("got %+v, %t, want ca to be kept as incomplete", ca, ok)
+	}
+	if !c.Sellable("ca") {
+		t.Error("expected an incomplete TLD to be sellable")
+	}
+	if _, ok := c.RequiredAgreements("ca", false); ok {
+		t.Error("expected no agreements for an incomplete TLD")
+	}
+	if _, err := c.Details(context.Background(), "ca"); err == nil {
+		t.Error("expected an error loading the details of ca")
+	}
+
+	service.fail()
+	ca, err := c.Details(context.Background(), ".ca")
+	if err != nil || ca.Incomplete {
+		t.Fatalf("got %+v, %v, want the details of ca to be loaded on demand", ca, err)
+	}
+	if got, _ := c.TLD("ca"); got.Incomplete {
+		t.Error("expected the details loaded on demand to be kept")
+	}
+
+	// details loaded before are kept when they can't be reloaded
+	service.fail("ca")
+	if err := c.Refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got, _ := c.TLD("ca"); got.Incomplete || len(got.AgreementKeys) != 1 {
+		t.Errorf("got %+v, want the previous details of ca", got)
+	}
+
+	if _, err := c.Details(context.Background(), "org"); util.FromError(err).ErrorType() != util.NotFound {
+		t.Errorf("got %v, want NotFound for a TLD not sold", err)
+	}
+}
+
+func TestCatalogRefreshFailureKeepsCatalog(t *testing.T) {
+	service := newFakeCatalogService()
+	c := NewTLDCatalog(service, WithCatalogPace(0))
+	if err := c.Refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	loadedAt := c.LoadedAt()
+
+	service.listErr = util.Error(util.Unavailable, "GoDaddy is down")
+	if err := c.Refresh(context.Background()); err == nil {
+		t.Fatal("expected the refresh to fail")
+	}
+	if len(c.List(TLDFilter{})) != 4 || c.LoadedAt() != loadedAt {
+		t.Error("expected the previous catalog to be kept")
+	}
+}
+
+func TestCatalogOnlyLoadsSelectedTLDs(t *testing.T) {
+	service := newFakeCatalogService()
+	c := NewTLDCatalog(service, WithCatalogTLDs(".com", "CA"), WithCatalogPace(0))
+	if err := c.Refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if got := len(c.List(TLDFilter{})); got != 2 {
+		t.Errorf("got %d TLDs, want 2", got)
+	}
+	// the list, then two agreements and a schema for each TLD
+	if service.calls != 7 {
+		t.Errorf("got %d calls, want 7", service.calls)
+	}
+}
+
+func TestCatalogPacesLoads(t *testing.T) {
+	c := NewTLDCatalog(newFakeCatalogService(), WithCatalogPace(20*time.Millisecond))
+	started := time.Now()
+	if err := c.Refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if elapsed := time.Since(started); elapsed < 60*time.Millisecond {
+		t.Errorf("loaded 4 TLDs in %s, want them paced", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := NewTLDCatalog(newFakeCatalogService(), WithCatalogPace(time.Hour)).Refresh(ctx); err == nil {
+		t.Error("expected the refresh to stop when ctx is done")
+	}
+}
+
+func TestCatalogWatchRetries(t *testing.T) {
+	service := newFakeCatalogService()
+	service.listErr = util.Error(util.Unavailable, "GoDaddy is down")
+	service.fail("us")
+	c := NewTLDCatalog(service, WithCatalogPace(0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		c.watch(ctx, time.Hour, time.Millisecond, 4*time.Millisecond)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	waitFor := func(what string, cond func() bool) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for !cond() {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s", what)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	// the first refresh fails, it is retried well before the regular refresh
+	service.mu.Lock()
+	service.listErr = nil
+	service.mu.Unlock()
+	waitFor("the catalog to be loaded", func() bool { return !c.LoadedAt().IsZero() })
+
+	us, _ := c.TLD("us")
+	if !us.Incomplete {
+		t.Fatal("expected us to be incomplete")
+	}
+	service.fail()
+	waitFor("us to be retried", func() bool {
+		us, _ := c.TLD("us")
+		return !us.Incomplete
+	})
+}
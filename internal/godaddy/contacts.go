@@ -0,0 +1,122 @@
+package godaddy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	httpService "github.com/glucn/godaddy/internal/http"
+	"github.com/glucn/godaddy/internal/redact"
+	"github.com/vendasta/gosdks/util"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const updateDomainContactsPathTemplate = "/domains/%s/contacts"
+
+// isZero tells whether no field of the contact is set
+func (c Contact) isZero() bool {
+	return c == Contact{}
+}
+
+// rolesOver returns the contacts of the four roles, falling back to the current contact of a role when neither
+// Contact nor an override of the role is set
+func (c DomainContacts) rolesOver(current roleContacts) roleContacts {
+	orDefault := func(override *Contact, current Contact) Contact {
+		if override != nil {
+			return *override
+		}
+		if c.Contact.isZero() {
+			return current
+		}
+		return c.Contact
+	}
+	return roleContacts{
+		ContactAdmin:      orDefault(c.Admin, current.ContactAdmin),
+		ContactBilling:    orDefault(c.Billing, current.ContactBilling),
+		ContactRegistrant: orDefault(c.Registrant, current.ContactRegistrant),
+		ContactTech:       orDefault(c.Tech, current.ContactTech),
+	}
+}
+
+// emptyRoles returns the JSON names of the roles left with an empty contact, which GoDaddy would store as is
+func (r roleContacts) emptyRoles() []string {
+	empty := []string{}
+	for role, contact := range map[string]Contact{
+		"contactAdmin":      r.ContactAdmin,
+		"contactBilling":    r.ContactBilling,
+		"contactRegistrant": r.ContactRegistrant,
+		"contactTech":       r.ContactTech,
+	} {
+		if contact.isZero() {
+			empty = append(empty, role)
+		}
+	}
+	sort.Strings(empty)
+	return empty
+}
+
+// validate checks that no role is left with an empty contact
+func (r roleContacts) validate() error {
+	if empty := r.emptyRoles(); len(empty) > 0 {
+		return util.Error(util.InvalidArgument, "A contact is required for %s, set contact or an override of the role", strings.Join(empty, ", "))
+	}
+	return nil
+}
+
+// current returns the contacts GoDaddy holds for the domain
+func (d DomainDetail) current() roleContacts {
+	orZero := func(c *Contact) Contact {
+		if c == nil {
+			return Contact{}
+		}
+		return *c
+	}
+	return roleContacts{
+		ContactAdmin:      orZero(d.ContactAdmin),
+		ContactBilling:    orZero(d.ContactBilling),
+		ContactRegistrant: orZero(d.ContactRegistrant),
+		ContactTech:       orZero(d.ContactTech),
+	}
+}
+
+func (s *Service) UpdateDomainContacts(ctx context.Context, domain string, contacts DomainContacts) error {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Domains)
+	defer cancel()
+
+	if err := validateDomain(domain); err != nil {
+		return err
+	}
+
+	// without contact, only the overridden roles change and the others keep their current contact
+	var current roleContacts
+	if contacts.Contact.isZero() {
+		if contacts.Admin == nil && contacts.Billing == nil && contacts.Registrant == nil && contacts.Tech == nil {
+			return util.Error(util.InvalidArgument, "Contact or an override of a role is required")
+		}
+		detail, err := s.GetDomain(ctx, domain)
+		if err != nil {
+			return err
+		}
+		current = detail.current()
+	}
+	roles := contacts.rolesOver(current)
+	if err := roles.validate(); err != nil {
+		return err
+	}
+
+	body := new(bytes.Buffer)
+	if err := json.NewEncoder(body).Encode(roles); err != nil {
+		redact.Errorf(ctx, "Error encoding contacts of domain %s: %v", domain, err)
+		return util.Error(util.Internal, "Error updating domain contacts")
+	}
+
+	url := s.endpoint.url(updateDomainContactsPathTemplate, domain)
+	res, err := s.call(ctx, http.MethodPatch, url, body, httpService.ContentTypeJSON, nil)
+	if err != nil {
+		redact.Errorf(ctx, "Error calling %s: %s", url, err.Error())
+		return convertError(err, "Error updating domain contacts")
+	}
+	httpService.DiscardBody(res)
+	return nil
+}
@@ -0,0 +1,114 @@
+package godaddy
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/vendasta/gosdks/util"
+	"net/http"
+	"testing"
+)
+
+func TestDomainContactsRoles(t *testing.T) {
+	agency := testContact
+	agency.Organization = "Agency"
+
+	roles := DomainContacts{Contact: testContact, Tech: &agency, Billing: &agency}.roles()
+	if roles.ContactRegistrant != testContact || roles.ContactAdmin != testContact {
+		t.Errorf("got %+v, want contact for the registrant and admin roles", roles)
+	}
+	if roles.ContactTech != agency || roles.ContactBilling != agency {
+		t.Errorf("got %+v, want the override for the tech and billing roles", roles)
+	}
+	if err := roles.validate(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	empty := DomainContacts{Tech: &agency}.roles()
+	if got := empty.emptyRoles(); len(got) != 3 || got[0] != "contactAdmin" || got[2] != "contactRegistrant" {
+		t.Errorf("got empty roles %v, want the roles without override", got)
+	}
+	if err := empty.validate(); util.FromError(err).ErrorType() != util.InvalidArgument {
+		t.Errorf("got error %v, want InvalidArgument", err)
+	}
+}
+
+func TestUpdateDomainContacts(t *testing.T) {
+	current := testContact
+	current.NameFirst = "Current"
+	tech := testContact
+	tech.NameFirst = "Tech"
+
+	cases := []struct {
+		name      string
+		contacts  DomainContacts
+		errorType util.ErrorType
+		want      roleContacts
+	}{
+		{
+			name:     "contact replaces every role",
+			contacts: DomainContacts{Contact: testContact},
+			want:     roleContacts{ContactAdmin: testContact, ContactBilling: testContact, ContactRegistrant: testContact, ContactTech: testContact},
+		},
+		{
+			name:     "override is merged into the current contacts",
+			contacts: DomainContacts{Tech: &tech},
+			want:     roleContacts{ContactAdmin: current, ContactBilling: current, ContactRegistrant: current, ContactTech: tech},
+		},
+		{
+			name:      "no contact",
+			contacts:  DomainContacts{},
+			errorType: util.InvalidArgument,
+		},
+		{
+			name:      "empty override",
+			contacts:  DomainContacts{Contact: testContact, Tech: &Contact{}},
+			errorType: util.InvalidArgument,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var sent *roleContacts
+			s := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodGet && r.URL.Path == "/v1/domains/example.com":
+					detail := DomainDetail{Domain: "example.com", ContactAdmin: &current, ContactBilling: &current, ContactRegistrant: &current, ContactTech: &current}
+					b, _ := json.Marshal(detail)
+					serveJSON(http.StatusOK, string(b))(w, r)
+				case r.Method == http.MethodPatch && r.URL.Path == "/v1/domains/example.com/contacts":
+					sent = &roleContacts{}
+					json.NewDecoder(r.Body).Decode(sent)
+					w.WriteHeader(http.StatusNoContent)
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+
+			err := s.UpdateDomainContacts(context.Background(), "example.com", c.contacts)
+			if c.errorType != 0 || err != nil {
+				if util.FromError(err).ErrorType() != c.errorType {
+					t.Fatalf("got error %v, want %s", err, c.errorType)
+				}
+				if sent != nil {
+					t.Fatalf("got contacts %+v sent, want none", sent)
+				}
+				return
+			}
+			if sent == nil || *sent != c.want {
+				t.Errorf("got contacts %+v sent, want %+v", sent, c.want)
+			}
+		})
+	}
+}
+
+func TestTransferDomainRejectsEmptyContacts(t *testing.T) {
+	s := newTestService(t, serveJSON(http.StatusOK, testSchema))
+
+	_, err := s.TransferDomain(context.Background(), "example.us", TransferRequest{
+		AuthCode: "Xa93kdLPq2",
+		Consent:  Consent{AgreementKeys: []string{"DNTA"}},
+		Period:   1,
+	})
+	if util.FromError(err).ErrorType() != util.InvalidArgument {
+		t.Fatalf("got error %v, want InvalidArgument", err)
+	}
+}
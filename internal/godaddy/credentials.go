@@ -0,0 +1,203 @@
+package godaddy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/glucn/godaddy/internal/redact"
+	"github.com/vendasta/gosdks/config"
+	"github.com/vendasta/gosdks/util"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	envAPIKey          = "GODADDY_API_KEY"
+	envAPISecret       = "GODADDY_API_SECRET"
+	envCredentialsFile = "GODADDY_CREDENTIALS_FILE"
+
+	// credentialsFileTemplate is where the secret holding the credentials of an environment is mounted by default
+	credentialsFileTemplate = "/etc/secrets/godaddy/%s/credentials.json"
+
+	credentialsReloadInterval = 30 * time.Second
+)
+
+// Credentials is a GoDaddy API key and secret
+type Credentials struct {
+	Key    string `json:"key"`
+	Secret string `json:"secret"`
+}
+
+// Authorization returns the value of the Authorization header for the credentials
+func (c Credentials) Authorization() string {
+	return fmt.Sprintf("sso-key %s:%s", c.Key, c.Secret)
+}
+
+func (c Credentials) valid() bool {
+	return c.Key != "" && c.Secret != ""
+}
+
+// CredentialProvider returns the credentials to authenticate GoDaddy calls with
+type CredentialProvider interface {
+	Credentials(ctx context.Context) (Credentials, error)
+}
+
+type staticCredentials struct {
+	credentials Credentials
+}
+
+// NewStaticCredentials returns a provider always returning the given credentials
+func NewStaticCredentials(c Credentials) CredentialProvider {
+	return &staticCredentials{credentials: c}
+}
+
+func (s *staticCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	return s.credentials, nil
+}
+
+// envCredentials are credentials issued for the GoDaddy API of an environment, either production or OTE, which the
+// keys of the other one are not valid on
+type envCredentials struct {
+	CredentialProvider
+	production bool
+}
+
+// CheckCredentialsEndpoint refuses to pair credentials loaded for an environment with the GoDaddy API of another one,
+// so that OTE keys are never sent to production and production keys never leave it. Credentials not loaded by
+// LoadCredentials are not tied to an environment.
+func CheckCredentialsEndpoint(p CredentialProvider, e Endpoint) error {
+	c, ok := p.(*envCredentials)
+	if !ok || c.production == e.production() {
+		return nil
+	}
+	if c.production {
+		return util.Error(util.FailedPrecondition, "GoDaddy production credentials must not be used with %s", e.BaseURL)
+	}
+	return util.Error(util.FailedPrecondition, "GoDaddy OTE credentials must not be used with the production API")
+}
+
+// FileCredentials reads the credentials from a mounted secret file, which holds either {"key": "", "secret": ""} or
+// key:secret, and reloads them when the file changes
+type FileCredentials struct {
+	path string
+
+	mu          sync.RWMutex
+	credentials Credentials
+	modTime     time.Time
+}
+
+// NewFileCredentials returns a provider reading the credentials from the file at path
+func NewFileCredentials(path string) (*FileCredentials, error) {
+	f := &FileCredentials{path: path}
+	if err := f.load(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Credentials returns the credentials last read from the file
+func (f *FileCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.credentials, nil
+}
+
+// Watch reloads the credentials whenever the file changes, until ctx is done. The last valid credentials are kept
+// when the file can't be read, so that a rotation in progress doesn't break outbound calls.
+func (f *FileCredentials) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			info, err := os.Stat(f.path)
+			if err != nil {
+				redact.Errorf(ctx, "Error checking GoDaddy credentials file %s: %v", f.path, err)
+				continue
+			}
+			f.mu.RLock()
+			changed := !info.ModTime().Equal(f.modTime)
+			f.mu.RUnlock()
+			if !changed {
+				continue
+			}
+			if err := f.load(); err != nil {
+				redact.Errorf(ctx, "Error reloading GoDaddy credentials from %s, keeping the previous ones: %v", f.path, err)
+				continue
+			}
+			redact.Infof(ctx, "Reloaded GoDaddy credentials from %s", f.path)
+		}
+	}
+}
+
+func (f *FileCredentials) load() error {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return util.Error(util.FailedPrecondition, "Error reading GoDaddy credentials file %s: %v", f.path, err)
+	}
+	buf, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return util.Error(util.FailedPrecondition, "Error reading GoDaddy credentials file %s: %v", f.path, err)
+	}
+
+	c, err := parseCredentials(buf)
+	if err != nil {
+		return util.Error(util.FailedPrecondition, "Error parsing GoDaddy credentials file %s: %v", f.path, err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.credentials = c
+	f.modTime = info.ModTime()
+	return nil
+}
+
+func parseCredentials(buf []byte) (Credentials, error) {
+	c := Credentials{}
+	content := strings.TrimSpace(string(buf))
+	if strings.HasPrefix(content, "{") {
+		if err := json.Unmarshal([]byte(content), &c); err != nil {
+			return Credentials{}, err
+		}
+	} else if parts := strings.SplitN(content, ":", 2); len(parts) == 2 {
+		c = Credentials{Key: strings.TrimSpace(parts[0]), Secret: strings.TrimSpace(parts[1])}
+	}
+	if !c.valid() {
+		return Credentials{}, fmt.Errorf("key and secret are required")
+	}
+	return c, nil
+}
+
+// LoadCredentials returns the GoDaddy credentials of the environment. They are read from the GODADDY_API_KEY and
+// GODADDY_API_SECRET environment variables if set, or else from the file at GODADDY_CREDENTIALS_FILE, which defaults
+// to the secret mounted for the environment. Credentials read from a file are reloaded until ctx is done.
+//
+// The credentials are tied to the GoDaddy API of the environment, see CheckCredentialsEndpoint.
+func LoadCredentials(ctx context.Context, env config.Env) (CredentialProvider, error) {
+	key, secret := os.Getenv(envAPIKey), os.Getenv(envAPISecret)
+	if key != "" || secret != "" {
+		c := Credentials{Key: key, Secret: secret}
+		if !c.valid() {
+			return nil, util.Error(util.FailedPrecondition, "Both %s and %s must be set", envAPIKey, envAPISecret)
+		}
+		return &envCredentials{CredentialProvider: NewStaticCredentials(c), production: env == config.Prod}, nil
+	}
+
+	path := os.Getenv(envCredentialsFile)
+	if path == "" {
+		path = fmt.Sprintf(credentialsFileTemplate, env.Name())
+	}
+	f, err := NewFileCredentials(path)
+	if err != nil {
+		return nil, util.Error(util.FailedPrecondition,
+			"GoDaddy credentials are missing for environment %s, set %s and %s or mount them at %s: %v",
+			env.Name(), envAPIKey, envAPISecret, path, err)
+	}
+	go f.Watch(ctx, credentialsReloadInterval)
+	return &envCredentials{CredentialProvider: f, production: env == config.Prod}, nil
+}
@@ -0,0 +1,156 @@
+package godaddy
+
+import (
+	"context"
+	"github.com/vendasta/gosdks/config"
+	"github.com/vendasta/gosdks/util"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCredentials(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		want    Credentials
+		invalid bool
+	}{
+		{name: "json", content: `{"key": "k1", "secret": "s1"}`, want: Credentials{Key: "k1", Secret: "s1"}},
+		{name: "json with spaces around", content: "\n  {\"key\": \"k1\", \"secret\": \"s1\"}\n", want: Credentials{Key: "k1", Secret: "s1"}},
+		{name: "key and secret", content: "k1:s1\n", want: Credentials{Key: "k1", Secret: "s1"}},
+		{name: "key and secret with spaces", content: " k1 : s1 ", want: Credentials{Key: "k1", Secret: "s1"}},
+		{name: "secret holding a colon", content: "k1:s1:x", want: Credentials{Key: "k1", Secret: "s1:x"}},
+		{name: "malformed json", content: `{"key": "k1"`, invalid: true},
+		{name: "json without secret", content: `{"key": "k1"}`, invalid: true},
+		{name: "no separator", content: "k1s1", invalid: true},
+		{name: "empty key", content: ":s1", invalid: true},
+		{name: "empty", content: "", invalid: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := parseCredentials([]byte(c.content))
+			if c.invalid {
+				if err == nil {
+					t.Errorf("got %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil || got != c.want {
+				t.Errorf("got %+v, %v, want %+v", got, err, c.want)
+			}
+		})
+	}
+}
+
+// writeCredentials writes the credentials file, moving its modification time forward so that every write is seen as
+// a change
+func writeCredentials(t *testing.T, path string, content string, modTime time.Time) {
+	t.Helper()
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestFileCredentialsWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	modTime := time.Now().Add(-time.Hour)
+	writeCredentials(t, path, `{"key": "k1", "secret": "s1"}`, modTime)
+	f, err := NewFileCredentials(path)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go f.Watch(ctx, time.Millisecond)
+
+	waitForKey := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			c, _ := f.Credentials(ctx)
+			if c.Key == want {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("got key %s, want %s", c.Key, want)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	// rotated without a restart, in the other format
+	writeCredentials(t, path, "k2:s2", modTime.Add(time.Minute))
+	waitForKey("k2")
+
+	// a bad rewrite keeps the last good credentials, until the file is fixed
+	writeCredentials(t, path, `{"key": "k3"`, modTime.Add(2*time.Minute))
+	time.Sleep(20 * time.Millisecond)
+	if c, _ := f.Credentials(ctx); c != (Credentials{Key: "k2", Secret: "s2"}) {
+		t.Errorf("got %+v, want the last good credentials", c)
+	}
+	writeCredentials(t, path, `{"key": "k3", "secret": "s3"}`, modTime.Add(3*time.Minute))
+	waitForKey("k3")
+}
+
+func TestNewFileCredentialsFailures(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := NewFileCredentials(filepath.Join(dir, "missing.json")); util.FromError(err).ErrorType() != util.FailedPrecondition {
+		t.Errorf("got %v, want FailedPrecondition for a missing file", err)
+	}
+	path := filepath.Join(dir, "credentials.json")
+	writeCredentials(t, path, "not credentials", time.Now())
+	if _, err := NewFileCredentials(path); util.FromError(err).ErrorType() != util.FailedPrecondition {
+		t.Errorf("got %v, want FailedPrecondition for a malformed file", err)
+	}
+}
+
+func TestLoadCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	writeCredentials(t, path, `{"key": "file-key", "secret": "file-secret"}`, time.Now())
+
+	cases := []struct {
+		name    string
+		key     string
+		secret  string
+		file    string
+		want    string
+		invalid bool
+	}{
+		{name: "environment variables win over the file", key: "env-key", secret: "env-secret", file: path, want: "env-key"},
+		{name: "file", file: path, want: "file-key"},
+		{name: "key without secret", key: "env-key", file: path, invalid: true},
+		{name: "secret without key", secret: "env-secret", invalid: true},
+		{name: "missing file", file: filepath.Join(filepath.Dir(path), "missing.json"), invalid: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Setenv(envAPIKey, c.key)
+			t.Setenv(envAPISecret, c.secret)
+			t.Setenv(envCredentialsFile, c.file)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			provider, err := LoadCredentials(ctx, config.Test)
+			if c.invalid {
+				if util.FromError(err).ErrorType() != util.FailedPrecondition || !strings.Contains(err.Error(), envAPIKey) {
+					t.Errorf("got %v, want FailedPrecondition telling how to set the credentials", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if got, _ := provider.Credentials(ctx); got.Key != c.want {
+				t.Errorf("got key %s, want %s", got.Key, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package godaddy
+
+import (
+	"context"
+	"fmt"
+	httpService "github.com/glucn/godaddy/internal/http"
+	"github.com/glucn/godaddy/internal/redact"
+	"github.com/vendasta/gosdks/util"
+	"github.com/vendasta/gosdks/validation"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	listDomainsPath       = "/domains"
+	getDomainPathTemplate = "/domains/%s"
+
+	// MaxListDomainsLimit is the largest page GoDaddy returns
+	MaxListDomainsLimit = 1000
+
+	// DefaultListDomainsLimit is the size of the page when ListDomainsOptions.Limit is 0, it is always sent so that
+	// a full page, which has a next one, is told apart from the last one
+	DefaultListDomainsLimit = 100
+)
+
+var (
+	// DomainStatusGroups are the status groups ListDomains can filter on
+	DomainStatusGroups = []string{"INACTIVE", "PRE_REGISTRATION", "REDEMPTION", "RENEWABLE", "VERIFICATION_ICANN", "VISIBLE"}
+
+	// DomainIncludes are the optional details ListDomains can include
+	DomainIncludes = []string{"authCode", "contacts", "nameServers"}
+)
+
+// DomainDetail is a domain owned by the account
+type DomainDetail struct {
+	DomainID          int64     `json:"domainId"`
+	Domain            string    `json:"domain"`
+	Status            string    `json:"status"`
+	Expires           time.Time `json:"expires"`
+	CreatedAt         time.Time `json:"createdAt"`
+	RenewAuto         bool      `json:"renewAuto"`
+	Renewable         bool      `json:"renewable"`
+	Locked            bool      `json:"locked"`
+	Privacy           bool      `json:"privacy"`
+	NameServers       []string  `json:"nameServers,omitempty"`
+	ContactAdmin      *Contact  `json:"contactAdmin,omitempty"`
+	ContactBilling    *Contact  `json:"contactBilling,omitempty"`
+	ContactRegistrant *Contact  `json:"contactRegistrant,omitempty"`
+	ContactTech       *Contact  `json:"contactTech,omitempty"`
+	// AuthCodeAvailable tells whether GoDaddy holds an auth code for the domain, the code itself is never exposed
+	AuthCodeAvailable bool `json:"authCodeAvailable"`
+}
+
+// ListDomainsOptions filters and paginates ListDomains
+type ListDomainsOptions struct {
+	// Statuses only returns domains with one of these statuses, ex: ACTIVE
+	Statuses []string
+	// StatusGroups only returns domains in one of these groups, see DomainStatusGroups
+	StatusGroups []string
+	// Limit is the size of the page, at most MaxListDomainsLimit, DefaultListDomainsLimit if 0
+	Limit int
+	// Marker is the domain the page starts after, NextMarker of the previous page
+	Marker string
+	// Includes adds optional details to the domains, see DomainIncludes
+	Includes []string
+}
+
+// DomainPage is a page of domains owned by the account
+type DomainPage struct {
+	Domains []DomainDetail `json:"domains"`
+	// NextMarker is the marker of the next page, empty on the last page
+	NextMarker string `json:"nextMarker,omitempty"`
+}
+
+// domainResponse is a domain as returned by GoDaddy
+type domainResponse struct {
+	DomainDetail
+	AuthCode string `json:"authCode"`
+}
+
+func (d domainResponse) detail() DomainDetail {
+	detail := d.DomainDetail
+	detail.AuthCodeAvailable = d.AuthCode != ""
+	return detail
+}
+
+func (o ListDomainsOptions) validate() error {
+	rules := []validation.Rule{
+		validation.IntBetween(o.Limit, 0, MaxListDomainsLimit, util.InvalidArgument, fmt.Sprintf("Limit must be between 1 and %d", MaxListDomainsLimit)),
+	}
+	for _, g := range o.StatusGroups {
+		rules = append(rules, validation.StringInSlice(g, DomainStatusGroups, util.InvalidArgument, fmt.Sprintf("Unknown status group %s", g)))
+	}
+	for _, i := range o.Includes {
+		rules = append(rules, validation.StringInSlice(i, DomainIncludes, util.InvalidArgument, fmt.Sprintf("Unknown include %s", i)))
+	}
+	return validation.NewValidator().Rule(rules...).Validate()
+}
+
+func (o ListDomainsOptions) params() []httpService.URLParam {
+	param := []httpService.URLParam{}
+	if len(o.Statuses) > 0 {
+		param = append(param, httpService.URLParam{Key: "statuses", Value: strings.Join(o.Statuses, ",")})
+	}
+	if len(o.StatusGroups) > 0 {
+		param = append(param, httpService.URLParam{Key: "statusGroups", Value: strings.Join(o.StatusGroups, ",")})
+	}
+	param = append(param, httpService.URLParam{Key: "limit", Value: fmt.Sprintf("%d", o.Limit)})
+	if o.Marker != "" {
+		param = append(param, httpService.URLParam{Key: "marker", Value: o.Marker})
+	}
+	if len(o.Includes) > 0 {
+		param = append(param, httpService.URLParam{Key: "includes", Value: strings.Join(o.Includes, ",")})
+	}
+	return param
+}
+
+func (s *Service) ListDomains(ctx context.Context, opts ListDomainsOptions) (DomainPage, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Domains)
+	defer cancel()
+
+	if err := opts.validate(); err != nil {
+		return DomainPage{}, err
+	}
+	if opts.Limit == 0 {
+		opts.Limit = DefaultListDomainsLimit
+	}
+
+	url := s.endpoint.url(listDomainsPath)
+	res, err := s.call(ctx, http.MethodGet, url, nil, "", opts.params())
+	if err != nil {
+		redact.Errorf(ctx, "Error calling %s: %s", url, err.Error())
+		return DomainPage{}, convertError(err, "Error listing domains")
+	}
+
+	body := make([]domainResponse, 0)
+	if err := httpService.DecodeJSON(res, &body); err != nil {
+		redact.Errorf(ctx, "Error decoding response body of %s: %v", res.Request.URL, err)
+		return DomainPage{}, err
+	}
+
+	page := DomainPage{Domains: make([]DomainDetail, len(body))}
+	for i, d := range body {
+		page.Domains[i] = d.detail()
+	}
+	if len(body) == opts.Limit {
+		page.NextMarker = body[len(body)-1].Domain
+	}
+	return page, nil
+}
+
+func (s *Service) GetDomain(ctx context.Context, domain string) (DomainDetail, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Domains)
+	defer cancel()
+
+	if err := validateDomain(domain); err != nil {
+		return DomainDetail{}, err
+	}
+
+	url := s.endpoint.url(getDomainPathTemplate, domain)
+	res, err := s.call(ctx, http.MethodGet, url, nil, "", nil)
+	if err != nil {
+		redact.Errorf(ctx, "Error calling %s: %s", url, err.Error())
+		return DomainDetail{}, convertError(err, "Error getting domain")
+	}
+
+	body := &domainResponse{}
+	if err := httpService.DecodeJSON(res, body); err != nil {
+		redact.Errorf(ctx, "Error decoding response body of %s: %v", res.Request.URL, err)
+		return DomainDetail{}, err
+	}
+	return body.detail(), nil
+}
+
+// validateDomain checks that a domain can safely be used in the path of a GoDaddy URL
+func validateDomain(domain string) error {
+	return validation.NewValidator().
+		Rule(validation.StringNotEmpty(domain, util.InvalidArgument, "Domain must not be empty")).
+		Rule(validation.BoolTrue(url.PathEscape(domain) == domain && !strings.Contains(domain, ".."), util.InvalidArgument, "Invalid domain "+domain)).
+		Validate()
+}
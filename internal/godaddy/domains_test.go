@@ -0,0 +1,265 @@
+package godaddy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/vendasta/gosdks/util"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serveDomains stands in for GET /v1/domains over the given sorted domains, honouring limit and marker, and records
+// the query of every request
+func serveDomains(domains []string, queries *[]url.Values) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/v1/domains" {
+			http.NotFound(w, r)
+			return
+		}
+		query := r.URL.Query()
+		*queries = append(*queries, query)
+
+		limit, err := strconv.Atoi(query.Get("limit"))
+		if err != nil {
+			limit = len(domains)
+		}
+		page := []map[string]interface{}{}
+		for _, d := range domains {
+			if d <= query.Get("marker") || len(page) == limit {
+				continue
+			}
+			page = append(page, map[string]interface{}{"domain": d, "status": "ACTIVE"})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(page)
+	}
+}
+
+func TestListDomainsPages(t *testing.T) {
+	cases := []struct {
+		name    string
+		domains []string
+		limit   int
+		want    [][]string
+	}{
+		{name: "partial last page", domains: []string{"a.com", "b.com", "c.com", "d.com", "e.com"}, limit: 2, want: [][]string{{"a.com", "b.com"}, {"c.com", "d.com"}, {"e.com"}}},
+		{name: "full last page", domains: []string{"a.com", "b.com", "c.com", "d.com"}, limit: 2, want: [][]string{{"a.com", "b.com"}, {"c.com", "d.com"}, {}}},
+		{name: "single page", domains: []string{"a.com", "b.com"}, limit: 5, want: [][]string{{"a.com", "b.com"}}},
+		{name: "no domains", domains: nil, limit: 5, want: [][]string{{}}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var queries []url.Values
+			s := newTestService(t, serveDomains(c.domains, &queries))
+
+			opts := ListDomainsOptions{Limit: c.limit}
+			var got [][]string
+			for i := 0; i <= len(c.want); i++ {
+				page, err := s.ListDomains(context.Background(), opts)
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				domains := []string{}
+				for _, d := range page.Domains {
+					domains = append(domains, d.Domain)
+				}
+				got = append(got, domains)
+				if page.NextMarker == "" {
+					break
+				}
+				if want := domains[len(domains)-1]; page.NextMarker != want {
+					t.Fatalf("got next marker %s, want %s", page.NextMarker, want)
+				}
+				opts.Marker = page.NextMarker
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got pages %v, want %v", got, c.want)
+			}
+			for _, q := range queries {
+				if q.Get("limit") != strconv.Itoa(c.limit) {
+					t.Errorf("got limit %q, want %d", q.Get("limit"), c.limit)
+				}
+			}
+		})
+	}
+}
+
+func TestListDomainsDefaultLimit(t *testing.T) {
+	domains := make([]string, DefaultListDomainsLimit+1)
+	for i := range domains {
+		domains[i] = fmt.Sprintf("domain-%03d.com", i)
+	}
+	var queries []url.Values
+	s := newTestService(t, serveDomains(domains, &queries))
+
+	page, err := s.ListDomains(context.Background(), ListDomainsOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(page.Domains) != DefaultListDomainsLimit || page.NextMarker != domains[DefaultListDomainsLimit-1] {
+		t.Errorf("got %d domains and next marker %q, want %d and %q", len(page.Domains), page.NextMarker, DefaultListDomainsLimit, domains[DefaultListDomainsLimit-1])
+	}
+	if got := queries[0].Get("limit"); got != strconv.Itoa(DefaultListDomainsLimit) {
+		t.Errorf("got limit %q, want %d", got, DefaultListDomainsLimit)
+	}
+}
+
+func TestListDomainsParams(t *testing.T) {
+	var queries []url.Values
+	s := newTestService(t, serveDomains(nil, &queries))
+
+	opts := ListDomainsOptions{
+		Statuses:     []string{"ACTIVE", "CANCELLED"},
+		StatusGroups: []string{"RENEWABLE", "VISIBLE"},
+		Limit:        10,
+		Marker:       "example.com",
+		Includes:     []string{"contacts", "nameServers"},
+	}
+	if _, err := s.ListDomains(context.Background(), opts); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	want := url.Values{
+		"statuses":     {"ACTIVE,CANCELLED"},
+		"statusGroups": {"RENEWABLE,VISIBLE"},
+		"limit":        {"10"},
+		"marker":       {"example.com"},
+		"includes":     {"contacts,nameServers"},
+	}
+	if !reflect.DeepEqual(queries[0], want) {
+		t.Errorf("got query %v, want %v", queries[0], want)
+	}
+}
+
+func TestListDomainsOptionsValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		opts    ListDomainsOptions
+		wantErr bool
+	}{
+		{name: "empty", opts: ListDomainsOptions{}},
+		{name: "largest limit", opts: ListDomainsOptions{Limit: MaxListDomainsLimit}},
+		{name: "limit too large", opts: ListDomainsOptions{Limit: MaxListDomainsLimit + 1}, wantErr: true},
+		{name: "negative limit", opts: ListDomainsOptions{Limit: -1}, wantErr: true},
+		{name: "known status groups", opts: ListDomainsOptions{StatusGroups: DomainStatusGroups}},
+		{name: "unknown status group", opts: ListDomainsOptions{StatusGroups: []string{"VISIBLE", "GONE"}}, wantErr: true},
+		{name: "known includes", opts: ListDomainsOptions{Includes: DomainIncludes}},
+		{name: "unknown include", opts: ListDomainsOptions{Includes: []string{"password"}}, wantErr: true},
+		{name: "any status", opts: ListDomainsOptions{Statuses: []string{"ACTIVE", "PENDING_TRANSFER"}}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.opts.validate()
+			if (err != nil) != c.wantErr {
+				t.Fatalf("got error %v, want error %t", err, c.wantErr)
+			}
+			if err != nil && util.FromError(err).ErrorType() != util.InvalidArgument {
+				t.Errorf("got error type %v, want InvalidArgument", util.FromError(err).ErrorType())
+			}
+		})
+	}
+}
+
+const testDomainResponse = `{
+	"domainId": 1234,
+	"domain": "example.com",
+	"status": "ACTIVE",
+	"expires": "2027-03-01T12:00:00Z",
+	"createdAt": "2024-03-01T12:00:00Z",
+	"renewAuto": true,
+	"renewable": true,
+	"locked": true,
+	"privacy": false,
+	"nameServers": ["ns41.domaincontrol.com", "ns42.domaincontrol.com"],
+	"authCode": "s3cr3t",
+	"contactRegistrant": {
+		"nameFirst": "Jane",
+		"nameLast": "Doe",
+		"email": "jane.doe@example.com",
+		"phone": "+1.3065551234",
+		"addressMailing": {"address1": "123 Main St", "city": "Saskatoon", "state": "SK", "postalCode": "S7S 1N5", "country": "CA"}
+	}
+}`
+
+func TestGetDomainMapsFields(t *testing.T) {
+	s := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/v1/domains/example.com" {
+			http.NotFound(w, r)
+			return
+		}
+		serveJSON(http.StatusOK, testDomainResponse)(w, r)
+	}))
+
+	got, err := s.GetDomain(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	registrant := testContact
+	want := DomainDetail{
+		DomainID:          1234,
+		Domain:            "example.com",
+		Status:            "ACTIVE",
+		Expires:           time.Date(2027, 3, 1, 12, 0, 0, 0, time.UTC),
+		CreatedAt:         time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		RenewAuto:         true,
+		Renewable:         true,
+		Locked:            true,
+		NameServers:       []string{"ns41.domaincontrol.com", "ns42.domaincontrol.com"},
+		ContactRegistrant: &registrant,
+		AuthCodeAvailable: true,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	// the auth code itself must never leave the service
+	buf, _ := json.Marshal(got)
+	if strings.Contains(string(buf), "s3cr3t") {
+		t.Errorf("auth code leaked in %s", buf)
+	}
+}
+
+func TestListDomainsMapsFields(t *testing.T) {
+	s := newTestService(t, serveJSON(http.StatusOK, "["+testDomainResponse+`, {"domain": "example.org", "status": "ACTIVE"}]`))
+
+	page, err := s.ListDomains(context.Background(), ListDomainsOptions{Includes: DomainIncludes})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(page.Domains) != 2 {
+		t.Fatalf("got %d domains, want 2", len(page.Domains))
+	}
+	if d := page.Domains[0]; !d.AuthCodeAvailable || d.ContactRegistrant == nil || len(d.NameServers) != 2 {
+		t.Errorf("got %+v, want the auth code, registrant and nameservers mapped", d)
+	}
+	if d := page.Domains[1]; d.AuthCodeAvailable || d.ContactRegistrant != nil || d.NameServers != nil {
+		t.Errorf("got %+v, want no optional details", d)
+	}
+}
+
+func TestGetDomainErrors(t *testing.T) {
+	cases := []struct {
+		name    string
+		domain  string
+		handler http.Handler
+		want    util.ErrorType
+	}{
+		{name: "empty domain", domain: "", handler: http.NotFoundHandler(), want: util.InvalidArgument},
+		{name: "path traversal", domain: "../shoppers", handler: http.NotFoundHandler(), want: util.InvalidArgument},
+		{name: "not found", domain: "example.com", handler: serveJSON(http.StatusNotFound, `{"code": "NOT_FOUND", "message": "Domain not found"}`), want: util.NotFound},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := newTestService(t, c.handler)
+			_, err := s.GetDomain(context.Background(), c.domain)
+			if got := util.FromError(err).ErrorType(); err == nil || got != c.want {
+				t.Errorf("got error %v of type %v, want %v", err, got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package godaddy
+
+import (
+	"fmt"
+	"github.com/vendasta/gosdks/config"
+	"github.com/vendasta/gosdks/util"
+	"net/url"
+	"strings"
+)
+
+const (
+	productionHost    = "api.godaddy.com"
+	productionBaseURL = "https://" + productionHost
+	oteBaseURL        = "https://api.ote-godaddy.com"
+	defaultAPIVersion = "v1"
+)
+
+// Endpoint is the GoDaddy API a service instance talks to
+type Endpoint struct {
+	BaseURL    string
+	APIVersion string
+}
+
+var (
+	// ProductionEndpoint is the GoDaddy production API, where purchases are charged
+	ProductionEndpoint = Endpoint{BaseURL: productionBaseURL, APIVersion: defaultAPIVersion}
+
+	// OTEEndpoint is the GoDaddy test environment (OTE), where nothing is charged
+	OTEEndpoint = Endpoint{BaseURL: oteBaseURL, APIVersion: defaultAPIVersion}
+)
+
+// EndpointForEnv returns the GoDaddy API matching the environment, only production talks to the production API
+func EndpointForEnv(env config.Env) Endpoint {
+	if env == config.Prod {
+		return ProductionEndpoint
+	}
+	return OTEEndpoint
+}
+
+// WithEndpoint overrides the endpoint derived from config.CurEnv(), for tests and local stand-ins of the GoDaddy API
+func WithEndpoint(e Endpoint) Option {
+	return func(s *Service) {
+		s.endpoint = e
+	}
+}
+
+// prefix returns the URL every call of the endpoint starts with
+func (e Endpoint) prefix() string {
+	return fmt.Sprintf("%s/%s", strings.TrimRight(e.BaseURL, "/"), strings.Trim(e.APIVersion, "/"))
+}
+
+func (e Endpoint) validate() error {
+	u, err := url.Parse(e.BaseURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return util.Error(util.InvalidArgument, "Invalid GoDaddy base URL %q", e.BaseURL)
+	}
+	if strings.Trim(e.APIVersion, "/") == "" {
+		return util.Error(util.InvalidArgument, "GoDaddy API version must not be empty")
+	}
+	return nil
+}
+
+// url returns the URL of a path of the endpoint, the path being formatted with a
+func (e Endpoint) url(path string, a ...interface{}) string {
+	return e.prefix() + fmt.Sprintf(path, a...)
+}
+
+// production tells whether the endpoint is the GoDaddy production API
+func (e Endpoint) production() bool {
+	u, err := url.Parse(e.BaseURL)
+	return err == nil && strings.EqualFold(u.Hostname(), productionHost)
+}
@@ -0,0 +1,138 @@
+package godaddy
+
+import (
+	"context"
+	"github.com/vendasta/gosdks/config"
+	"github.com/vendasta/gosdks/util"
+	"net/http"
+	"testing"
+)
+
+func TestEndpointForEnv(t *testing.T) {
+	for _, c := range []struct {
+		env  config.Env
+		want Endpoint
+	}{
+		{env: config.Prod, want: ProductionEndpoint},
+		{env: config.Demo, want: OTEEndpoint},
+		{env: config.Test, want: OTEEndpoint},
+		{env: config.Local, want: OTEEndpoint},
+	} {
+		if got := EndpointForEnv(c.env); got != c.want {
+			t.Errorf("EndpointForEnv(%s) = %v, want %v", c.env.Name(), got, c.want)
+		}
+	}
+}
+
+func TestEndpointValidate(t *testing.T) {
+	cases := []struct {
+		name     string
+		endpoint Endpoint
+		valid    bool
+	}{
+		{name: "production", endpoint: ProductionEndpoint, valid: true},
+		{name: "ote", endpoint: OTEEndpoint, valid: true},
+		{name: "stand-in", endpoint: Endpoint{BaseURL: "http://127.0.0.1:8080/", APIVersion: "/v1/"}, valid: true},
+		{name: "no scheme", endpoint: Endpoint{BaseURL: "api.godaddy.com", APIVersion: "v1"}},
+		{name: "no host", endpoint: Endpoint{BaseURL: "https://", APIVersion: "v1"}},
+		{name: "unparsable", endpoint: Endpoint{BaseURL: "https://api.godaddy.com:port", APIVersion: "v1"}},
+		{name: "no version", endpoint: Endpoint{BaseURL: productionBaseURL, APIVersion: "/"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.endpoint.validate()
+			if c.valid && err != nil {
+				t.Errorf("unexpected error %v", err)
+			}
+			if !c.valid && util.FromError(err).ErrorType() != util.InvalidArgument {
+				t.Errorf("got %v, want InvalidArgument", err)
+			}
+		})
+	}
+
+	if got, want := (Endpoint{BaseURL: "http://127.0.0.1:8080/", APIVersion: "/v1/"}).url("/domains/%s", "example.com"), "http://127.0.0.1:8080/v1/domains/example.com"; got != want {
+		t.Errorf("got URL %s, want %s", got, want)
+	}
+}
+
+func TestCheckCredentialsEndpoint(t *testing.T) {
+	static := NewStaticCredentials(Credentials{Key: "key", Secret: "secret"})
+	production := &envCredentials{CredentialProvider: static, production: true}
+	ote := &envCredentials{CredentialProvider: static}
+	standIn := Endpoint{BaseURL: "http://127.0.0.1:8080", APIVersion: "v1"}
+
+	cases := []struct {
+		name        string
+		credentials CredentialProvider
+		endpoint    Endpoint
+		valid       bool
+	}{
+		{name: "production keys on production", credentials: production, endpoint: ProductionEndpoint, valid: true},
+		{name: "ote keys on ote", credentials: ote, endpoint: OTEEndpoint, valid: true},
+		{name: "ote keys on a stand-in", credentials: ote, endpoint: standIn, valid: true},
+		{name: "ote keys on production", credentials: ote, endpoint: ProductionEndpoint},
+		{name: "ote keys on production spelled differently", credentials: ote, endpoint: Endpoint{BaseURL: "https://API.GoDaddy.com:443/", APIVersion: "v1"}},
+		{name: "production keys on ote", credentials: production, endpoint: OTEEndpoint},
+		{name: "production keys on a stand-in", credentials: production, endpoint: standIn},
+		{name: "keys of no environment", credentials: static, endpoint: ProductionEndpoint, valid: true},
+		{name: "no keys", credentials: nil, endpoint: ProductionEndpoint, valid: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := CheckCredentialsEndpoint(c.credentials, c.endpoint)
+			if c.valid && err != nil {
+				t.Errorf("unexpected error %v", err)
+			}
+			if !c.valid && util.FromError(err).ErrorType() != util.FailedPrecondition {
+				t.Errorf("got %v, want FailedPrecondition", err)
+			}
+		})
+	}
+}
+
+func TestLoadCredentialsAreTiedToTheEnvironment(t *testing.T) {
+	t.Setenv(envAPIKey, "key")
+	t.Setenv(envAPISecret, "secret")
+
+	for _, c := range []struct {
+		env        config.Env
+		production bool
+	}{
+		{env: config.Prod, production: true},
+		{env: config.Demo},
+		{env: config.Test},
+	} {
+		provider, err := LoadCredentials(context.Background(), c.env)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if err := CheckCredentialsEndpoint(provider, ProductionEndpoint); (err == nil) != c.production {
+			t.Errorf("credentials of %s on production: got %v", c.env.Name(), err)
+		}
+		if err := CheckCredentialsEndpoint(provider, OTEEndpoint); (err == nil) == c.production {
+			t.Errorf("credentials of %s on OTE: got %v", c.env.Name(), err)
+		}
+	}
+}
+
+func TestServiceRefusesMismatchedCredentials(t *testing.T) {
+	calls := 0
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		serveJSON(http.StatusOK, `[]`)(w, r)
+	})
+	production := &envCredentials{CredentialProvider: NewStaticCredentials(Credentials{Key: "key", Secret: "secret"}), production: true}
+
+	s := newTestService(t, handler, WithCredentials(production))
+	if _, err := s.ListDomains(context.Background(), ListDomainsOptions{}); util.FromError(err).ErrorType() != util.FailedPrecondition {
+		t.Errorf("got %v, want FailedPrecondition", err)
+	}
+	if calls != 0 {
+		t.Errorf("got %d calls, want production credentials never sent to a stand-in", calls)
+	}
+
+	s = newTestService(t, handler, WithEndpoint(Endpoint{BaseURL: "not a url", APIVersion: "v1"}))
+	if _, err := s.ListDomains(context.Background(), ListDomainsOptions{}); util.FromError(err).ErrorType() != util.InvalidArgument {
+		t.Errorf("got %v, want InvalidArgument", err)
+	}
+}
@@ -0,0 +1,150 @@
+package godaddy
+
+import (
+	"encoding/json"
+	"fmt"
+	httpService "github.com/glucn/godaddy/internal/http"
+	"github.com/vendasta/gosdks/util"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"strings"
+)
+
+// FieldError is a problem GoDaddy reported on a single field of a request
+type FieldError struct {
+	Code        string `json:"code"`
+	Message     string `json:"message,omitempty"`
+	Path        string `json:"path"`
+	PathRelated string `json:"pathRelated,omitempty"`
+}
+
+// APIError is an error returned by the GoDaddy API
+type APIError struct {
+	StatusCode    int          `json:"-"`
+	Code          string       `json:"code"`
+	Message       string       `json:"message"`
+	Fields        []FieldError `json:"fields,omitempty"`
+	RetryAfterSec int64        `json:"retryAfterSec,omitempty"`
+}
+
+// codeErrorTypes maps the GoDaddy error codes which need a more precise error type than their status code
+var codeErrorTypes = map[string]util.ErrorType{
+	"UNAVAILABLE_DOMAIN":     util.FailedPrecondition,
+	"TOO_MANY_REQUESTS":      util.ResourceExhausted,
+	"QUOTA_EXCEEDED":         util.ResourceExhausted,
+	"UNABLE_TO_AUTHENTICATE": util.Unauthenticated,
+	"ACCESS_DENIED":          util.PermissionDenied,
+	"NOT_FOUND":              util.NotFound,
+	"INVALID_BODY":           util.InvalidArgument,
+	"MISMATCH_FORMAT":        util.InvalidArgument,
+}
+
+func (e *APIError) Error() string {
+	if e == nil {
+		return ""
+	}
+	msg := fmt.Sprintf("GoDaddy error %s (%d): %s", e.Code, e.StatusCode, e.Message)
+	for _, f := range e.Fields {
+		msg += fmt.Sprintf("; %s: %s", f.Path, f.Message)
+	}
+	return msg
+}
+
+// ErrorType returns the util error type matching the GoDaddy error
+func (e *APIError) ErrorType() util.ErrorType {
+	if t, ok := codeErrorTypes[e.Code]; ok {
+		return t
+	}
+
+	switch e.StatusCode {
+	case http.StatusPaymentRequired:
+		return util.FailedPrecondition
+	case http.StatusUnprocessableEntity:
+		for _, f := range e.Fields {
+			if strings.HasPrefix(f.Path, "consent") {
+				return util.FailedPrecondition
+			}
+		}
+		return util.InvalidArgument
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return util.Unavailable
+	}
+	return util.StatusCodeToGRPCError(e.StatusCode)
+}
+
+// HTTPCode returns the http status code to answer with when the GoDaddy error is returned to a client
+func (e *APIError) HTTPCode() int {
+	return util.Error(e.ErrorType(), "%s", e.Message).HTTPCode()
+}
+
+// GRPCStatus lets util.FromError and the grpc status package convert the GoDaddy error
+func (e *APIError) GRPCStatus() *status.Status {
+	return status.New(util.ErrorTypeToGRPCCode(e.ErrorType()), e.Message)
+}
+
+// parseAPIError returns the GoDaddy error held by an error of the http service, or nil if there is none
+func parseAPIError(err error) *APIError {
+	httpErr, ok := err.(*httpService.Error)
+	if !ok || httpErr == nil {
+		return nil
+	}
+
+	apiErr := &APIError{}
+	if json.Unmarshal(httpErr.RawBody, apiErr) != nil || apiErr.Code == "" {
+		apiErr = &APIError{
+			Code: strings.ToUpper(strings.Replace(http.StatusText(httpErr.StatusCode), " ", "_", -1)),
+		}
+	}
+	apiErr.StatusCode = httpErr.StatusCode
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(httpErr.StatusCode)
+	}
+	return apiErr
+}
+
+// convertError returns the error to hand to callers for an error of the http service. Errors without a more precise
+// type become an Internal error with the given message.
+func convertError(err error, message string) error {
+	if apiErr := parseAPIError(err); apiErr != nil {
+		return apiErr
+	}
+	if serviceErr, ok := err.(util.ServiceError); ok && serviceErr.ErrorType() != util.Internal {
+		return serviceErr
+	}
+	return util.Error(util.Internal, "%s", message)
+}
+
+// ValidationError is returned when a request is rejected before reaching GoDaddy, ex: a purchase not matching the
+// purchase schema of the TLD
+type ValidationError struct {
+	Message string
+	Fields  []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msg := e.Message
+	for _, f := range e.Fields {
+		msg += fmt.Sprintf("; %s: %s", f.Path, f.Message)
+	}
+	return msg
+}
+
+// ErrorType returns the util error type of the validation error
+func (e *ValidationError) ErrorType() util.ErrorType {
+	for _, f := range e.Fields {
+		if strings.HasPrefix(f.Path, "consent") {
+			return util.FailedPrecondition
+		}
+	}
+	return util.InvalidArgument
+}
+
+// HTTPCode returns the http status code to answer with when the validation error is returned to a client
+func (e *ValidationError) HTTPCode() int {
+	return util.Error(e.ErrorType(), "%s", e.Message).HTTPCode()
+}
+
+// GRPCStatus lets util.FromError and the grpc status package convert the validation error
+func (e *ValidationError) GRPCStatus() *status.Status {
+	return status.New(util.ErrorTypeToGRPCCode(e.ErrorType()), e.Message)
+}
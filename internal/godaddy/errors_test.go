@@ -0,0 +1,77 @@
+package godaddy
+
+import (
+	httpService "github.com/glucn/godaddy/internal/http"
+	"github.com/vendasta/gosdks/util"
+	"net/http"
+	"testing"
+)
+
+func TestAPIErrorType(t *testing.T) {
+	cases := []struct {
+		name       string
+		err        *APIError
+		errorType  util.ErrorType
+		statusCode int
+	}{
+		{name: "unavailable domain", err: &APIError{StatusCode: 422, Code: "UNAVAILABLE_DOMAIN"}, errorType: util.FailedPrecondition, statusCode: http.StatusPreconditionFailed},
+		{name: "quota", err: &APIError{StatusCode: 429, Code: "QUOTA_EXCEEDED"}, errorType: util.ResourceExhausted, statusCode: http.StatusTooManyRequests},
+		{name: "bad credentials", err: &APIError{StatusCode: 401, Code: "UNABLE_TO_AUTHENTICATE"}, errorType: util.Unauthenticated, statusCode: http.StatusUnauthorized},
+		{name: "access denied", err: &APIError{StatusCode: 403, Code: "ACCESS_DENIED"}, errorType: util.PermissionDenied, statusCode: http.StatusForbidden},
+		{name: "not found", err: &APIError{StatusCode: 404, Code: "NOT_FOUND"}, errorType: util.NotFound, statusCode: http.StatusNotFound},
+		{name: "payment required", err: &APIError{StatusCode: 402, Code: "PAYMENT_REQUIRED"}, errorType: util.FailedPrecondition, statusCode: http.StatusPreconditionFailed},
+		{name: "invalid field", err: &APIError{StatusCode: 422, Code: "MISSING_PROPERTY", Fields: []FieldError{{Path: "contactAdmin.email"}}}, errorType: util.InvalidArgument, statusCode: http.StatusBadRequest},
+		{name: "invalid consent", err: &APIError{StatusCode: 422, Code: "MISSING_PROPERTY", Fields: []FieldError{{Path: "consent.agreementKeys"}}}, errorType: util.FailedPrecondition, statusCode: http.StatusPreconditionFailed},
+		{name: "upstream failure", err: &APIError{StatusCode: 500, Code: "INTERNAL_SERVER_ERROR"}, errorType: util.Unavailable, statusCode: http.StatusServiceUnavailable},
+		{name: "upstream timeout", err: &APIError{StatusCode: 504, Code: "GATEWAY_TIMEOUT"}, errorType: util.Unavailable, statusCode: http.StatusServiceUnavailable},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.err.ErrorType(); got != c.errorType {
+				t.Errorf("ErrorType() = %s, want %s", got, c.errorType)
+			}
+			if got := c.err.HTTPCode(); got != c.statusCode {
+				t.Errorf("HTTPCode() = %d, want %d", got, c.statusCode)
+			}
+			if got := util.FromError(c.err).ErrorType(); got != c.errorType {
+				t.Errorf("util.FromError(err).ErrorType() = %s, want %s", got, c.errorType)
+			}
+		})
+	}
+}
+
+func TestValidationErrorType(t *testing.T) {
+	invalid := &ValidationError{Fields: []FieldError{{Path: "period"}}}
+	if got := invalid.ErrorType(); got != util.InvalidArgument {
+		t.Errorf("ErrorType() = %s, want InvalidArgument", got)
+	}
+	consent := &ValidationError{Fields: []FieldError{{Path: "period"}, {Path: "consent.agreementKeys"}}}
+	if got := consent.ErrorType(); got != util.FailedPrecondition {
+		t.Errorf("ErrorType() = %s, want FailedPrecondition for a consent problem", got)
+	}
+}
+
+func TestConvertError(t *testing.T) {
+	body := []byte(`{"code":"INVALID_BODY","message":"Request body doesn't fulfill schema","fields":[{"code":"MISMATCH_FORMAT","path":"domain"}]}`)
+	err := convertError(&httpService.Error{StatusCode: 422, RawBody: body}, "Error purchasing domain")
+
+	apiErr, ok := err.(*APIError)
+	if !ok {
+		t.Fatalf("got %T, want *APIError", err)
+	}
+	if apiErr.Code != "INVALID_BODY" || len(apiErr.Fields) != 1 || apiErr.StatusCode != 422 {
+		t.Errorf("got %+v, want the parsed GoDaddy error", apiErr)
+	}
+
+	unparsable := convertError(&httpService.Error{StatusCode: 503, RawBody: []byte("<html>")}, "Error purchasing domain").(*APIError)
+	if unparsable.Code != "SERVICE_UNAVAILABLE" || unparsable.Message != "Service Unavailable" {
+		t.Errorf("got %+v, want the code and message derived from the status", unparsable)
+	}
+
+	if got := util.FromError(convertError(util.Error(util.DeadlineExceeded, "late"), "Error")).ErrorType(); got != util.DeadlineExceeded {
+		t.Errorf("got %s, want the service error to be kept", got)
+	}
+	if got := convertError(util.Error(util.Internal, "secret details"), "Error purchasing domain").Error(); got != "Error purchasing domain" {
+		t.Errorf("got %q, want the internal error to be replaced", got)
+	}
+}
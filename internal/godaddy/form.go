@@ -0,0 +1,183 @@
+package godaddy
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// contactPaths maps the contact properties of a purchase schema to the contacts of a purchase request. The
+// registrant describes the contact filling every role, the other roles are optional overrides of it.
+var contactPaths = map[string]string{
+	"contactRegistrant": "contacts.contact",
+}
+
+// skippedFormFields are the properties of a purchase schema that are not filled by the end-user
+var skippedFormFields = map[string]bool{
+	"consent":        true,
+	"contactAdmin":   true,
+	"contactBilling": true,
+	"contactTech":    true,
+}
+
+// fieldLabels are the labels of the fields whose name doesn't read well once split
+var fieldLabels = map[string]string{
+	"address1":    "Address line 1",
+	"address2":    "Address line 2",
+	"fax":         "Fax number",
+	"nameFirst":   "First name",
+	"nameLast":    "Last name",
+	"nameMiddle":  "Middle name",
+	"nameServers": "Nameservers",
+	"postalCode":  "Postal code",
+	"renewAuto":   "Renew automatically",
+	"state":       "State or province",
+}
+
+// fieldOrder is the order fields are shown in, the others follow in alphabetical order
+var fieldOrder = []string{
+	"domain", "period", "privacy", "renewAuto", "nameServers",
+	"nameFirst", "nameMiddle", "nameLast", "organization", "jobTitle", "email", "phone", "fax",
+	"addressMailing", "address1", "address2", "city", "state", "postalCode", "country",
+}
+
+// FormField is a field of a purchase form. Path is where its value goes in the body of /purchase-domain, ex:
+// contacts.contact.addressMailing.country or extra.legalType.
+type FormField struct {
+	Path      string      `json:"path"`
+	Label     string      `json:"label"`
+	Type      string      `json:"type"`
+	Format    string      `json:"format,omitempty"`
+	Pattern   string      `json:"pattern,omitempty"`
+	Enum      []string    `json:"enum,omitempty"`
+	Required  bool        `json:"required"`
+	Minimum   *float64    `json:"minimum,omitempty"`
+	Maximum   *float64    `json:"maximum,omitempty"`
+	MinLength *int        `json:"minLength,omitempty"`
+	MaxLength *int        `json:"maxLength,omitempty"`
+	Default   interface{} `json:"default,omitempty"`
+	// Extra tells whether the field is specific to the TLD
+	Extra bool `json:"extra,omitempty"`
+}
+
+// FormAgreement is an agreement the end-user must accept, its key goes in the agreementKeys of /purchase-domain
+type FormAgreement struct {
+	Key   string `json:"key"`
+	Title string `json:"title"`
+	URL   string `json:"url,omitempty"`
+}
+
+// PurchaseForm describes, independently of any UI, what to ask the end-user to purchase a domain under a TLD
+type PurchaseForm struct {
+	TLD        string          `json:"tld"`
+	Fields     []FormField     `json:"fields"`
+	Agreements []FormAgreement `json:"agreements"`
+}
+
+// NewPurchaseForm builds the purchase form of a TLD from its purchase schema and agreements
+func NewPurchaseForm(tld string, schema PurchaseSchema, agreements []Agreement) PurchaseForm {
+	form := PurchaseForm{TLD: tld, Fields: []FormField{}, Agreements: make([]FormAgreement, len(agreements))}
+	for i, a := range agreements {
+		form.Agreements[i] = FormAgreement{Key: a.Key, Title: a.Title, URL: a.URL}
+	}
+
+	required := stringSet(schema.Required)
+	for _, name := range sortedFieldNames(schema.Properties) {
+		if skippedFormFields[name] {
+			continue
+		}
+		path := name
+		extra := !standardPurchaseFields[name]
+		if p, ok := contactPaths[name]; ok {
+			path = p
+		} else if extra {
+			path = "extra." + name
+		}
+		schema.appendFields(&form.Fields, path, name, schema.Properties[name], required[name], extra, 0)
+	}
+	return form
+}
+
+// appendFields appends the field of a property, or the fields of the model it refers to
+func (s PurchaseSchema) appendFields(fields *[]FormField, path string, name string, p SchemaProperty, required bool, extra bool, depth int) {
+	if p.Ref != "" {
+		model, ok := s.Models[p.Ref[strings.LastIndex(p.Ref, "/")+1:]]
+		if !ok || depth >= maxSchemaDepth {
+			return
+		}
+		modelRequired := stringSet(model.Required)
+		for _, n := range sortedFieldNames(model.Properties) {
+			// the fields of an optional object are only required when the object is given, so they are left optional
+			s.appendFields(fields, path+"."+n, n, model.Properties[n], required && modelRequired[n], extra, depth+1)
+		}
+		return
+	}
+
+	fieldType := p.Type
+	if fieldType == "" {
+		fieldType = "string"
+	}
+	*fields = append(*fields, FormField{
+		Path:      path,
+		Label:     fieldLabel(name),
+		Type:      fieldType,
+		Format:    p.Format,
+		Pattern:   p.Pattern,
+		Enum:      p.Enum,
+		Required:  required,
+		Minimum:   p.Minimum,
+		Maximum:   p.Maximum,
+		MinLength: p.MinLength,
+		MaxLength: p.MaxLength,
+		Default:   p.DefaultValue,
+		Extra:     extra,
+	})
+}
+
+// fieldLabel returns the label of a field, ex: Job title for jobTitle
+func fieldLabel(name string) string {
+	if label, ok := fieldLabels[name]; ok {
+		return label
+	}
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case i == 0:
+			b.WriteRune(unicode.ToUpper(r))
+		case unicode.IsUpper(r):
+			b.WriteRune(' ')
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// sortedFieldNames returns the names of the properties in the order they are shown in
+func sortedFieldNames(properties map[string]SchemaProperty) []string {
+	rank := make(map[string]int, len(fieldOrder))
+	for i, name := range fieldOrder {
+		rank[name] = i + 1
+	}
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		ri, rj := rank[names[i]], rank[names[j]]
+		if ri != rj {
+			return ri != 0 && (rj == 0 || ri < rj)
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+func stringSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
@@ -0,0 +1,98 @@
+package godaddy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewPurchaseForm(t *testing.T) {
+	agreements := []Agreement{{Key: "DNRA", Title: "Registration agreement", URL: "https://example.com/dnra", Content: "<p>DNRA</p>"}}
+	form := NewPurchaseForm("us", parseTestSchema(t), agreements)
+
+	fields := map[string]FormField{}
+	for _, f := range form.Fields {
+		fields[f.Path] = f
+	}
+	cases := []struct {
+		path     string
+		required bool
+		extra    bool
+	}{
+		{path: "domain", required: true},
+		{path: "period"},
+		{path: "contacts.contact.nameFirst", required: true},
+		{path: "contacts.contact.jobTitle"},
+		{path: "contacts.contact.addressMailing.country", required: true},
+		{path: "contacts.contact.addressMailing.postalCode"},
+		{path: "extra.nexusCategory", required: true, extra: true},
+		{path: "extra.appPurpose", extra: true},
+	}
+	for _, c := range cases {
+		f, ok := fields[c.path]
+		if !ok {
+			t.Errorf("%s: missing field", c.path)
+			continue
+		}
+		if f.Required != c.required || f.Extra != c.extra {
+			t.Errorf("%s: got required %t and extra %t, want %t and %t", c.path, f.Required, f.Extra, c.required, c.extra)
+		}
+	}
+	for path := range fields {
+		if strings.HasPrefix(path, "consent") || strings.HasPrefix(path, "contactAdmin") || strings.HasPrefix(path, "contactRegistrant") {
+			t.Errorf("got field %s, want it left to the server", path)
+		}
+	}
+	if f := fields["extra.nexusCategory"]; len(f.Enum) != 3 || f.Label != "Nexus category" {
+		t.Errorf("got %+v, want the enum and label of the nexus", f)
+	}
+	if f := fields["period"]; f.Type != "integer" || f.Minimum == nil || *f.Minimum != 1 || f.Maximum == nil || *f.Maximum != 5 {
+		t.Errorf("got %+v, want the limits of the period", f)
+	}
+
+	if len(form.Agreements) != 1 || form.Agreements[0] != (FormAgreement{Key: "DNRA", Title: "Registration agreement", URL: "https://example.com/dnra"}) {
+		t.Errorf("got agreements %+v", form.Agreements)
+	}
+}
+
+func TestNewPurchaseFormOptionalModel(t *testing.T) {
+	schema := parseTestSchema(t)
+	schema.Properties["contactRegistrant"] = SchemaProperty{Ref: "#/definitions/Contact"}
+	schema.Required = []string{"domain"}
+	form := NewPurchaseForm("us", schema, nil)
+
+	for _, f := range form.Fields {
+		if strings.HasPrefix(f.Path, "contacts.contact.") && f.Required {
+			t.Errorf("got %s required, want the fields of an optional contact to be optional", f.Path)
+		}
+	}
+	if form.Agreements == nil {
+		t.Error("got nil agreements, want an empty list")
+	}
+}
+
+func TestSortedFieldNames(t *testing.T) {
+	properties := map[string]SchemaProperty{}
+	for _, name := range []string{"nexusCategory", "email", "appPurpose", "period", "domain", "nameLast", "nameFirst", "address1"} {
+		properties[name] = SchemaProperty{}
+	}
+
+	got := strings.Join(sortedFieldNames(properties), ",")
+	want := "domain,period,nameFirst,nameLast,email,address1,appPurpose,nexusCategory"
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestFieldLabel(t *testing.T) {
+	for name, want := range map[string]string{
+		"nameFirst":     "First name",
+		"postalCode":    "Postal code",
+		"jobTitle":      "Job title",
+		"nexusCategory": "Nexus category",
+		"email":         "Email",
+	} {
+		if got := fieldLabel(name); got != want {
+			t.Errorf("fieldLabel(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import "context"
 
 type AddressMailing struct {
 	Address1   string `json:"address1"`
+	Address2   string `json:"address2,omitempty"`
 	City       string `json:"city"`
 	Country    string `json:"country"`
 	PostalCode string `json:"postalCode"`
@@ -13,11 +14,37 @@ type AddressMailing struct {
 type Contact struct {
 	AddressMailing AddressMailing `json:"addressMailing"`
 	Email          string         `json:"email"`
+	Fax            string         `json:"fax,omitempty"`
+	JobTitle       string         `json:"jobTitle,omitempty"`
 	NameFirst      string         `json:"nameFirst"`
 	NameLast       string         `json:"nameLast"`
+	Organization   string         `json:"organization,omitempty"`
 	Phone          string         `json:"phone"`
 }
 
+// DomainContacts are the contacts of the four roles of a domain. Contact fills every role without an override, so
+// that an agency can be the tech and billing contact of a domain registered to its client. An empty contact is never
+// sent, UpdateDomainContacts keeps the current contact of the roles without one.
+type DomainContacts struct {
+	Contact    Contact  `json:"contact"`
+	Registrant *Contact `json:"registrant,omitempty"`
+	Admin      *Contact `json:"admin,omitempty"`
+	Tech       *Contact `json:"tech,omitempty"`
+	Billing    *Contact `json:"billing,omitempty"`
+}
+
+// roleContacts is how GoDaddy expects the contacts of a domain
+type roleContacts struct {
+	ContactAdmin      Contact `json:"contactAdmin"`
+	ContactBilling    Contact `json:"contactBilling"`
+	ContactRegistrant Contact `json:"contactRegistrant"`
+	ContactTech       Contact `json:"contactTech"`
+}
+
+func (c DomainContacts) roles() roleContacts {
+	return c.rolesOver(roleContacts{})
+}
+
 type Consent struct {
 	AgreedAt      string   `json:"agreedAt"`
 	AgreedBy      string   `json:"agreedBy"`
@@ -33,12 +60,25 @@ type DNSRecord struct {
 
 // Interface holds the GoDaddy APIs
 type Interface interface {
-	GetDomainAvailabilityAndPrice(ctx context.Context, domain string) (bool, int64, error)
-	PurchaseDomain(ctx context.Context, domain string, contact Contact, consent Consent) error
-	GetDomainSuggestions(ctx context.Context, domain string) ([]string, error)
-	GetPurchaseSchema(ctx context.Context, tld string) ([]string, error)
-	ListTLDs(ctx context.Context) ([]string, error)
-	GetPurchaseAgreement(ctx context.Context, tld string) ([]string, error)
+	GetDomainAvailability(ctx context.Context, domain string) (AvailabilityResult, error)
+	GetDomainsAvailability(ctx context.Context, domains []string, checkType CheckType) (BulkAvailability, error)
+	PurchaseDomain(ctx context.Context, domain string, contacts DomainContacts, consent Consent, opts PurchaseOptions) (PurchaseResult, error)
+	ValidatePurchase(ctx context.Context, domain string, contacts DomainContacts, consent Consent, opts PurchaseOptions) ([]FieldError, error)
+	GetDomainSuggestions(ctx context.Context, opts SuggestOptions) ([]string, error)
+	GetPurchaseSchema(ctx context.Context, tld string) (PurchaseSchema, error)
+	ListTLDs(ctx context.Context) ([]TLD, error)
+	GetPurchaseAgreement(ctx context.Context, tld string, opts AgreementOptions) ([]Agreement, error)
+	BuildConsent(ctx context.Context, domain string, opts AgreementOptions, agreementKeys []string, agreedBy string) (Consent, error)
 	GetDNSRecords(ctx context.Context, domain string, dnsType string) ([]DNSRecord, error)
 	PutDNSRecord(ctx context.Context, domain string, record DNSRecord) error
+	ListDomains(ctx context.Context, opts ListDomainsOptions) (DomainPage, error)
+	GetDomain(ctx context.Context, domain string) (DomainDetail, error)
+	RenewDomain(ctx context.Context, domain string, period int) (OrderResult, error)
+	SetAutoRenew(ctx context.Context, domain string, renewAuto bool) error
+	TransferDomain(ctx context.Context, domain string, req TransferRequest) (OrderResult, error)
+	GetTransferStatus(ctx context.Context, domain string) (TransferStatus, error)
+	UpdateDomainContacts(ctx context.Context, domain string, contacts DomainContacts) error
+	GetNameservers(ctx context.Context, domain string) ([]string, error)
+	SetNameservers(ctx context.Context, domain string, nameservers []string) ([]string, error)
+	ResetNameservers(ctx context.Context, domain string) ([]string, error)
 }
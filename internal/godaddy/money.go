@@ -0,0 +1,102 @@
+package godaddy
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// MicrosPerUnit is the number of micro-units in a unit of a currency. GoDaddy prices are in micro-units, ex:
+// 12990000 is 12.99 USD.
+const MicrosPerUnit = 1000000
+
+// currencyDigits holds the number of decimals of the currencies that don't have 2
+var currencyDigits = map[string]int{
+	"BHD": 3,
+	"CLP": 0,
+	"ISK": 0,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"VND": 0,
+}
+
+// Money is an amount in a currency, held in micro-units so that no precision is lost
+type Money struct {
+	Currency string `json:"currency"`
+	Micros   int64  `json:"micros"`
+}
+
+// NewMoney returns the amount of micro-units of a currency, ex: NewMoney("USD", 12990000) for 12.99 USD
+func NewMoney(currency string, micros int64) Money {
+	return Money{Currency: strings.ToUpper(currency), Micros: micros}
+}
+
+// MoneyFromMinorUnits returns the amount of minor units of a currency, ex: MoneyFromMinorUnits("USD", 1299) for
+// 12.99 USD
+func MoneyFromMinorUnits(currency string, amount int64) Money {
+	m := NewMoney(currency, 0)
+	m.Micros = amount * MicrosPerUnit / pow10(m.digits())
+	return m
+}
+
+// digits is the number of decimals of the currency
+func (m Money) digits() int {
+	if d, ok := currencyDigits[m.Currency]; ok {
+		return d
+	}
+	return 2
+}
+
+// MinorUnits returns the amount in minor units of the currency, ex: cents for USD, rounded half away from zero
+func (m Money) MinorUnits() int64 {
+	return int64(math.Round(float64(m.Micros) * float64(pow10(m.digits())) / MicrosPerUnit))
+}
+
+// Units returns the amount in units of the currency, ex: 12.99 for 12.99 USD. Only use it for display or
+// approximations, floats can't hold every amount exactly.
+func (m Money) Units() float64 {
+	return float64(m.Micros) / MicrosPerUnit
+}
+
+// Amount formats the amount with the number of decimals of the currency, ex: 12.99
+func (m Money) Amount() string {
+	digits := m.digits()
+	minor := m.MinorUnits()
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+	if digits == 0 {
+		return fmt.Sprintf("%s%d", sign, minor)
+	}
+	scale := pow10(digits)
+	return fmt.Sprintf("%s%d.%0*d", sign, minor/scale, digits, minor%scale)
+}
+
+// String formats the amount followed by the currency, ex: 12.99 USD
+func (m Money) String() string {
+	if m.Currency == "" {
+		return m.Amount()
+	}
+	return m.Amount() + " " + m.Currency
+}
+
+// MarshalJSON adds the formatted amount to the JSON of the money, for display by clients
+func (m Money) MarshalJSON() ([]byte, error) {
+	type money Money
+	return json.Marshal(struct {
+		money
+		Amount string `json:"amount"`
+	}{money: money(m), Amount: m.Amount()})
+}
+
+func pow10(n int) int64 {
+	p := int64(1)
+	for i := 0; i < n; i++ {
+		p *= 10
+	}
+	return p
+}
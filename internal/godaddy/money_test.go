@@ -0,0 +1,79 @@
+package godaddy
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMoney(t *testing.T) {
+	cases := []struct {
+		money  Money
+		minor  int64
+		amount string
+		str    string
+	}{
+		{money: NewMoney("usd", 12990000), minor: 1299, amount: "12.99", str: "12.99 USD"},
+		{money: NewMoney("USD", 12995000), minor: 1300, amount: "13.00", str: "13.00 USD"},
+		{money: NewMoney("USD", -12995000), minor: -1300, amount: "-13.00", str: "-13.00 USD"},
+		{money: NewMoney("USD", 50000), minor: 5, amount: "0.05", str: "0.05 USD"},
+		{money: NewMoney("USD", 0), minor: 0, amount: "0.00", str: "0.00 USD"},
+		{money: NewMoney("JPY", 1500000000), minor: 1500, amount: "1500", str: "1500 JPY"},
+		{money: NewMoney("KWD", 1234500), minor: 1235, amount: "1.235", str: "1.235 KWD"},
+		{money: NewMoney("KWD", -1234500), minor: -1235, amount: "-1.235", str: "-1.235 KWD"},
+		{money: NewMoney("", 12990000), minor: 1299, amount: "12.99", str: "12.99"},
+	}
+	for _, c := range cases {
+		if got := c.money.MinorUnits(); got != c.minor {
+			t.Errorf("%+v.MinorUnits() = %d, want %d", c.money, got, c.minor)
+		}
+		if got := c.money.Amount(); got != c.amount {
+			t.Errorf("%+v.Amount() = %q, want %q", c.money, got, c.amount)
+		}
+		if got := c.money.String(); got != c.str {
+			t.Errorf("%+v.String() = %q, want %q", c.money, got, c.str)
+		}
+	}
+}
+
+func TestMoneyFromMinorUnits(t *testing.T) {
+	cases := []struct {
+		currency string
+		amount   int64
+		micros   int64
+	}{
+		{currency: "USD", amount: 1299, micros: 12990000},
+		{currency: "jpy", amount: 1500, micros: 1500000000},
+		{currency: "BHD", amount: 1235, micros: 1235000},
+		{currency: "EUR", amount: -250, micros: -2500000},
+	}
+	for _, c := range cases {
+		m := MoneyFromMinorUnits(c.currency, c.amount)
+		if m.Micros != c.micros {
+			t.Errorf("MoneyFromMinorUnits(%q, %d).Micros = %d, want %d", c.currency, c.amount, m.Micros, c.micros)
+		}
+		if m.MinorUnits() != c.amount {
+			t.Errorf("MoneyFromMinorUnits(%q, %d).MinorUnits() = %d, want the amount back", c.currency, c.amount, m.MinorUnits())
+		}
+	}
+}
+
+func TestMoneyUnits(t *testing.T) {
+	if got := NewMoney("USD", 12990000).Units(); got != 12.99 {
+		t.Errorf("Units() = %v, want 12.99", got)
+	}
+}
+
+func TestMoneyMarshalJSON(t *testing.T) {
+	b, err := json.Marshal(NewMoney("USD", 12990000))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if want := `{"currency":"USD","micros":12990000,"amount":"12.99"}`; string(b) != want {
+		t.Errorf("got %s, want %s", b, want)
+	}
+
+	m := Money{}
+	if err := json.Unmarshal(b, &m); err != nil || m != NewMoney("USD", 12990000) {
+		t.Errorf("got %+v, %v, want the money back", m, err)
+	}
+}
@@ -0,0 +1,102 @@
+package godaddy
+
+import (
+	"context"
+	"github.com/glucn/godaddy/internal/redact"
+	"github.com/vendasta/gosdks/util"
+	"regexp"
+	"strings"
+)
+
+const (
+	// MinNameservers is the number of distinct nameservers registries require for a delegation
+	MinNameservers = 2
+
+	// goDaddyDNSSuffix is the domain of the nameservers GoDaddy hosts DNS on, ex: ns01.domaincontrol.com
+	goDaddyDNSSuffix = ".domaincontrol.com"
+)
+
+// DefaultNameservers are the nameservers GoDaddy hosts DNS on for the account, see WithDefaultNameservers
+var DefaultNameservers = []string{"ns01.domaincontrol.com", "ns02.domaincontrol.com"}
+
+// WithDefaultNameservers overrides the DefaultNameservers ResetNameservers delegates domains to, GoDaddy assigns a
+// pair of domaincontrol.com nameservers to each account
+func WithDefaultNameservers(nameservers ...string) Option {
+	return func(s *Service) {
+		s.defaultNameservers = nameservers
+	}
+}
+
+// hostnamePattern is a fully qualified hostname: dot separated labels of letters, digits and inner hyphens
+var hostnamePattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// NormalizeNameservers lower-cases the nameservers, strips their trailing dot and drops duplicates. It fails unless
+// every entry is a valid hostname and at least MinNameservers remain.
+func NormalizeNameservers(nameservers []string) ([]string, error) {
+	seen := make(map[string]bool, len(nameservers))
+	normalized := make([]string, 0, len(nameservers))
+	for _, ns := range nameservers {
+		host := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(ns)), ".")
+		if len(host) > 253 || !hostnamePattern.MatchString(host) {
+			return nil, util.Error(util.InvalidArgument, "Invalid nameserver %q", ns)
+		}
+		if seen[host] {
+			continue
+		}
+		seen[host] = true
+		normalized = append(normalized, host)
+	}
+	if len(normalized) < MinNameservers {
+		return nil, util.Error(util.InvalidArgument, "At least %d distinct nameservers are required", MinNameservers)
+	}
+	return normalized, nil
+}
+
+// DelegatedToGoDaddy tells whether the nameservers of a domain, as GoDaddy has them on record, are the ones GoDaddy
+// hosts DNS on, in which case GetDNSRecords and PutDNSRecord are meant to take effect. It says nothing about what DNS
+// resolves: a new delegation takes a while to reach the registry, and resolvers keep the previous one until it expires.
+func DelegatedToGoDaddy(nameservers []string) bool {
+	if len(nameservers) == 0 {
+		return false
+	}
+	for _, ns := range nameservers {
+		if !strings.HasSuffix(strings.TrimSuffix(strings.ToLower(ns), "."), goDaddyDNSSuffix) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Service) GetNameservers(ctx context.Context, domain string) ([]string, error) {
+	detail, err := s.GetDomain(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	return detail.NameServers, nil
+}
+
+// SetNameservers delegates the domain to the given nameservers, and returns them as they were submitted to GoDaddy
+func (s *Service) SetNameservers(ctx context.Context, domain string, nameservers []string) ([]string, error) {
+	normalized, err := NormalizeNameservers(nameservers)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.updateDomain(ctx, domain, map[string]interface{}{"nameServers": normalized}); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// ResetNameservers delegates the domain back to the default nameservers of the service, the ones GoDaddy hosts DNS on,
+// and returns them
+func (s *Service) ResetNameservers(ctx context.Context, domain string) ([]string, error) {
+	normalized, err := NormalizeNameservers(s.defaultNameservers)
+	if err != nil {
+		redact.Errorf(ctx, "Invalid default nameservers %v: %v", s.defaultNameservers, err)
+		return nil, util.Error(util.FailedPrecondition, "Default nameservers are misconfigured")
+	}
+	if err := s.updateDomain(ctx, domain, map[string]interface{}{"nameServers": normalized}); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
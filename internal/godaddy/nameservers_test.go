@@ -0,0 +1,83 @@
+package godaddy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeNameservers(t *testing.T) {
+	cases := []struct {
+		name        string
+		nameservers []string
+		want        []string
+	}{
+		{name: "normalized", nameservers: []string{" NS1.Example.com. ", "ns2.example.com"}, want: []string{"ns1.example.com", "ns2.example.com"}},
+		{name: "duplicates dropped", nameservers: []string{"ns1.example.com", "ns1.example.com.", "ns2.example.com"}, want: []string{"ns1.example.com", "ns2.example.com"}},
+		{name: "too few", nameservers: []string{"ns1.example.com", "NS1.example.com"}},
+		{name: "invalid hostname", nameservers: []string{"ns1.example.com", "ns_2.example.com"}},
+		{name: "ip address", nameservers: []string{"ns1.example.com", "203.0.113.7"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := NormalizeNameservers(c.nameservers)
+			if c.want == nil {
+				if err == nil {
+					t.Fatalf("got %v, want an error", got)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %v, %v, want %v", got, err, c.want)
+			}
+		})
+	}
+}
+
+func TestDelegatedToGoDaddy(t *testing.T) {
+	for _, c := range []struct {
+		nameservers []string
+		want        bool
+	}{
+		{nameservers: []string{"ns41.domaincontrol.com", "NS42.DOMAINCONTROL.COM."}, want: true},
+		{nameservers: []string{"ns41.domaincontrol.com", "ns1.example.com"}, want: false},
+		{nameservers: []string{"ns1.domaincontrol.com.example.com", "ns2.domaincontrol.com.example.com"}, want: false},
+		{nameservers: nil, want: false},
+	} {
+		if got := DelegatedToGoDaddy(c.nameservers); got != c.want {
+			t.Errorf("DelegatedToGoDaddy(%v) = %t, want %t", c.nameservers, got, c.want)
+		}
+	}
+}
+
+func TestResetNameserversSendsDefaultNameservers(t *testing.T) {
+	var sent map[string][]string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch || r.URL.Path != "/v1/domains/example.com" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	s := newTestService(t, handler)
+	got, err := s.ResetNameservers(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(sent["nameServers"], DefaultNameservers) || !reflect.DeepEqual(got, DefaultNameservers) {
+		t.Errorf("got nameservers %v, returned %v, want %v", sent["nameServers"], got, DefaultNameservers)
+	}
+
+	s = newTestService(t, handler, WithDefaultNameservers("NS51.domaincontrol.com", "ns52.domaincontrol.com"))
+	got, err = s.ResetNameservers(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if want := []string{"ns51.domaincontrol.com", "ns52.domaincontrol.com"}; !reflect.DeepEqual(sent["nameServers"], want) || !reflect.DeepEqual(got, want) {
+		t.Errorf("got nameservers %v, returned %v, want %v", sent["nameServers"], got, want)
+	}
+}
@@ -0,0 +1,181 @@
+package godaddy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	httpService "github.com/glucn/godaddy/internal/http"
+	"github.com/glucn/godaddy/internal/redact"
+	"github.com/vendasta/gosdks/util"
+	"net/http"
+)
+
+const (
+	purchaseDomainPath   = "/domains/purchase"
+	validatePurchasePath = "/domains/purchase/validate"
+)
+
+// PurchaseOptions configures a domain at registration time
+type PurchaseOptions struct {
+	// Period is the registration period in years, 1 if 0
+	Period int
+	// Privacy hides the contacts of the domain from WHOIS
+	Privacy bool
+	// RenewAuto renews the domain before it expires
+	RenewAuto bool
+	// NameServers delegates the domain, to the GoDaddy nameservers if empty
+	NameServers []string
+	// Extra holds the values of the fields specific to the TLD, see PurchaseSchema.ExtraFields
+	Extra map[string]interface{}
+}
+
+// PurchaseResult is what was bought by PurchaseDomain
+type PurchaseResult struct {
+	Domain string `json:"domain"`
+	Period int    `json:"period"`
+	OrderResult
+}
+
+// purchaseRequest checks a purchase against the purchase schema of the TLD and encodes the body shared by
+// PurchaseDomain and ValidatePurchase. The problems found are returned as a *ValidationError.
+func (s *Service) purchaseRequest(ctx context.Context, domain string, contacts DomainContacts, consent Consent, opts *PurchaseOptions) (*bytes.Buffer, error) {
+	if err := validateDomain(domain); err != nil {
+		return nil, err
+	}
+	if opts.Period == 0 {
+		opts.Period = defaultMinPeriod
+	}
+	var err error
+	if len(opts.NameServers) > 0 {
+		if opts.NameServers, err = NormalizeNameservers(opts.NameServers); err != nil {
+			return nil, err
+		}
+	}
+	schema, err := s.schema(ctx, tldOf(domain))
+	if err != nil {
+		return nil, err
+	}
+
+	type purchaseDomainBody struct {
+		roleContacts
+		Consent     Consent  `json:"consent"`
+		Domain      string   `json:"domain"`
+		NameServers []string `json:"nameServers,omitempty"`
+		Period      int      `json:"period"`
+		Privacy     bool     `json:"privacy"`
+		RenewAuto   bool     `json:"renewAuto"`
+	}
+
+	roles := contacts.roles()
+	bodyData, err := purchaseBodyMap(purchaseDomainBody{
+		roleContacts: roles,
+		Consent:      consent,
+		Domain:       domain,
+		NameServers:  opts.NameServers,
+		Period:       opts.Period,
+		Privacy:      opts.Privacy,
+		RenewAuto:    opts.RenewAuto,
+	})
+	if err != nil {
+		redact.Errorf(ctx, "Error encoding purchase body for domain %s: %v", domain, err)
+		return nil, util.Error(util.Internal, "Error encoding purchase")
+	}
+
+	problems := []FieldError{}
+	// an empty contact is reported once, as missing, rather than for each of its required fields
+	for _, role := range roles.emptyRoles() {
+		delete(bodyData, role)
+		if !stringInSlice(role, schema.Required) {
+			problems = append(problems, FieldError{Code: "MISSING_PROPERTY", Message: "Field is required", Path: role})
+		}
+	}
+	for name, value := range opts.Extra {
+		if _, ok := schema.Properties[name]; !ok || standardPurchaseFields[name] {
+			problems = append(problems, FieldError{Code: "UNKNOWN_PROPERTY", Message: "Field is not an extra field of the TLD", Path: name})
+			continue
+		}
+		bodyData[name] = value
+	}
+	problems = append(problems, schema.Validate(bodyData)...)
+	// the schema of some TLDs has no period limits, the default ones apply then
+	if period := schema.Properties["period"]; period.Minimum == nil && period.Maximum == nil {
+		if limits := schema.PeriodLimits(); opts.Period < limits.Min || opts.Period > limits.Max {
+			problems = append(problems, FieldError{
+				Code:    "OUT_OF_RANGE",
+				Message: fmt.Sprintf("Period must be between %d and %d years", limits.Min, limits.Max),
+				Path:    "period",
+			})
+		}
+	}
+	if len(problems) > 0 {
+		return nil, &ValidationError{Message: fmt.Sprintf("Purchase of domain %s is invalid", domain), Fields: problems}
+	}
+
+	body := new(bytes.Buffer)
+	if err := json.NewEncoder(body).Encode(bodyData); err != nil {
+		redact.Errorf(ctx, "Error encoding purchase body for domain %s: %v", domain, err)
+		return nil, util.Error(util.Internal, "Error encoding purchase")
+	}
+	return body, nil
+}
+
+func (s *Service) PurchaseDomain(ctx context.Context, domain string, contacts DomainContacts, consent Consent, opts PurchaseOptions) (PurchaseResult, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Purchase)
+	defer cancel()
+
+	body, err := s.purchaseRequest(ctx, domain, contacts, consent, &opts)
+	if err != nil {
+		return PurchaseResult{}, err
+	}
+
+	res, err := s.call(ctx, http.MethodPost, s.endpoint.url(purchaseDomainPath), body, httpService.ContentTypeJSON, nil)
+
+	if err != nil {
+		redact.Errorf(ctx, "Error calling /v1/domains/purchase: %s", err.Error())
+		return PurchaseResult{}, convertError(err, "Error purchasing domain")
+	}
+
+	order := orderResponse{}
+	if err := httpService.DecodeJSON(res, &order); err != nil {
+		redact.Errorf(ctx, "Error decoding response body of %s: %v", res.Request.URL, err)
+		return PurchaseResult{}, err
+	}
+
+	return PurchaseResult{Domain: domain, Period: opts.Period, OrderResult: order.result()}, nil
+}
+
+// ValidatePurchase checks whether PurchaseDomain would accept the same arguments, without placing an order. The
+// purchase is checked against the purchase schema of the TLD first, then by GoDaddy. The problems found are returned
+// as field errors, none meaning the purchase is valid. An error is only returned when the validation itself could not
+// be done.
+func (s *Service) ValidatePurchase(ctx context.Context, domain string, contacts DomainContacts, consent Consent, opts PurchaseOptions) ([]FieldError, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Purchase)
+	defer cancel()
+
+	body, err := s.purchaseRequest(ctx, domain, contacts, consent, &opts)
+	if validationErr, ok := err.(*ValidationError); ok {
+		return validationErr.Fields, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// validating a purchase has no side effect, it is safe to retry despite being a POST
+	url := s.endpoint.url(validatePurchasePath)
+	res, err := s.call(httpService.WithRetryNonIdempotent(ctx), http.MethodPost, url, body, httpService.ContentTypeJSON, nil)
+	if err != nil {
+		apiErr := parseAPIError(err)
+		if apiErr != nil && (apiErr.StatusCode == http.StatusBadRequest || apiErr.StatusCode == http.StatusUnprocessableEntity) {
+			if len(apiErr.Fields) > 0 {
+				return apiErr.Fields, nil
+			}
+			return []FieldError{{Code: apiErr.Code, Message: apiErr.Message}}, nil
+		}
+		redact.Errorf(ctx, "Error calling %s: %s", url, err.Error())
+		return nil, convertError(err, "Error validating purchase")
+	}
+	httpService.DiscardBody(res)
+
+	return nil, nil
+}
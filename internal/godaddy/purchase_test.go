@@ -0,0 +1,193 @@
+package godaddy
+
+import (
+	"context"
+	"encoding/json"
+	httpService "github.com/glucn/godaddy/internal/http"
+	"github.com/vendasta/gosdks/util"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// testPurchaseExtra are the extra fields making a purchase of example.us valid against testSchema
+var testPurchaseExtra = map[string]interface{}{"nexusCategory": "C11"}
+
+func TestValidatePurchaseFieldErrors(t *testing.T) {
+	cases := []struct {
+		name      string
+		status    int
+		body      string
+		want      []FieldError
+		fails     bool
+		errorType util.ErrorType
+	}{
+		{name: "valid", status: http.StatusNoContent},
+		{
+			name:   "400 with fields",
+			status: http.StatusBadRequest,
+			body:   `{"code": "INVALID_BODY", "message": "Request body doesn't fulfill schema", "fields": [{"code": "MISMATCH_FORMAT", "message": "Invalid phone", "path": "contactRegistrant.phone"}]}`,
+			want:   []FieldError{{Code: "MISMATCH_FORMAT", Message: "Invalid phone", Path: "contactRegistrant.phone"}},
+		},
+		{
+			name:   "422 with fields",
+			status: http.StatusUnprocessableEntity,
+			body:   `{"code": "INVALID_CONSENT", "message": "Consent is invalid", "fields": [{"code": "MISSING_AGREEMENT", "path": "consent.agreementKeys", "pathRelated": "domain"}]}`,
+			want:   []FieldError{{Code: "MISSING_AGREEMENT", Path: "consent.agreementKeys", PathRelated: "domain"}},
+		},
+		{
+			name:   "422 without fields",
+			status: http.StatusUnprocessableEntity,
+			body:   `{"code": "UNAVAILABLE_DOMAIN", "message": "The domain is not available"}`,
+			want:   []FieldError{{Code: "UNAVAILABLE_DOMAIN", Message: "The domain is not available"}},
+		},
+		{
+			name:   "400 without a GoDaddy error",
+			status: http.StatusBadRequest,
+			body:   `not json`,
+			want:   []FieldError{{Code: "BAD_REQUEST", Message: "Bad Request"}},
+		},
+		{name: "403", status: http.StatusForbidden, body: `{"code": "ACCESS_DENIED", "message": "Denied"}`, fails: true, errorType: util.PermissionDenied},
+		{name: "500", status: http.StatusInternalServerError, body: `{"code": "INTERNAL_SERVER_ERROR", "message": "Oops"}`, fails: true, errorType: util.Unavailable},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/v1/domains/purchase/validate" {
+					t.Errorf("got %s %s, want the purchase to be validated only", r.Method, r.URL.Path)
+				}
+				serveJSON(c.status, c.body)(w, r)
+			}), WithSchemaSource(staticSchemas{"us": parseTestSchema(t)}))
+
+			got, err := s.ValidatePurchase(context.Background(), "example.us", DomainContacts{Contact: testContact}, testConsent, PurchaseOptions{Extra: testPurchaseExtra})
+			if c.fails {
+				if util.FromError(err).ErrorType() != c.errorType {
+					t.Errorf("got %v, %v, want %s", got, err, c.errorType)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %+v, want %+v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestValidatePurchaseChecksSchemaFirst(t *testing.T) {
+	s := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("got %s %s, want the purchase to be rejected without calling GoDaddy", r.Method, r.URL.Path)
+		http.NotFound(w, r)
+	}), WithSchemaSource(staticSchemas{"us": parseTestSchema(t)}))
+
+	got, err := s.ValidatePurchase(context.Background(), "example.us", DomainContacts{Contact: testContact}, testConsent, PurchaseOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(got) != 1 || got[0].Code != "MISSING_PROPERTY" || got[0].Path != "nexusCategory" {
+		t.Errorf("got %+v, want nexusCategory missing", got)
+	}
+}
+
+func TestValidatePurchaseIsRetried(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	httpClient := httpService.NewService(server.Client(), httpService.WithRetryPolicy(httpService.RetryPolicy{MaxAttempts: 2}))
+	s := NewService(httpClient,
+		WithEndpoint(Endpoint{BaseURL: server.URL, APIVersion: "v1"}),
+		WithCredentials(NewStaticCredentials(Credentials{Key: "key", Secret: "secret"})),
+		WithSchemaSource(staticSchemas{"us": parseTestSchema(t)}),
+	)
+
+	got, err := s.ValidatePurchase(context.Background(), "example.us", DomainContacts{Contact: testContact}, testConsent, PurchaseOptions{Extra: testPurchaseExtra})
+	if err != nil || len(got) != 0 {
+		t.Fatalf("got %+v, %v, want a valid purchase", got, err)
+	}
+	if attempts != 2 {
+		t.Errorf("got %d attempts, want the validation to be retried", attempts)
+	}
+}
+
+func TestPurchaseDomain(t *testing.T) {
+	contact := map[string]interface{}{}
+	buf, _ := json.Marshal(testContact)
+	json.Unmarshal(buf, &contact)
+	consent := map[string]interface{}{}
+	buf, _ = json.Marshal(testConsent)
+	json.Unmarshal(buf, &consent)
+
+	cases := []struct {
+		name   string
+		opts   PurchaseOptions
+		period int
+		want   map[string]interface{}
+	}{
+		{
+			name:   "defaults",
+			opts:   PurchaseOptions{Extra: testPurchaseExtra},
+			period: 1,
+			want:   map[string]interface{}{"period": 1.0, "privacy": false, "renewAuto": false, "nexusCategory": "C11"},
+		},
+		{
+			name:   "options",
+			opts:   PurchaseOptions{Period: 3, Privacy: true, RenewAuto: true, NameServers: []string{"NS1.Example.com.", "ns2.example.com"}, Extra: map[string]interface{}{"nexusCategory": "C21", "appPurpose": "P1"}},
+			period: 3,
+			want: map[string]interface{}{
+				"period":        3.0,
+				"privacy":       true,
+				"renewAuto":     true,
+				"nameServers":   []interface{}{"ns1.example.com", "ns2.example.com"},
+				"nexusCategory": "C21",
+				"appPurpose":    "P1",
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var sent map[string]interface{}
+			s := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/v1/domains/purchase" {
+					t.Errorf("got %s %s, want the domain to be purchased", r.Method, r.URL.Path)
+				}
+				json.NewDecoder(r.Body).Decode(&sent)
+				serveJSON(http.StatusOK, `{"orderId": 1234, "itemCount": 1, "total": 12990000, "currency": "usd"}`)(w, r)
+			}), WithSchemaSource(staticSchemas{"us": parseTestSchema(t)}))
+
+			got, err := s.PurchaseDomain(context.Background(), "example.us", DomainContacts{Contact: testContact}, testConsent, c.opts)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			want := PurchaseResult{Domain: "example.us", Period: c.period, OrderResult: OrderResult{OrderID: 1234, ItemCount: 1, Total: NewMoney("USD", 12990000)}}
+			if got != want {
+				t.Errorf("got %+v, want %+v", got, want)
+			}
+
+			wantBody := map[string]interface{}{
+				"domain":            "example.us",
+				"consent":           consent,
+				"contactAdmin":      contact,
+				"contactBilling":    contact,
+				"contactRegistrant": contact,
+				"contactTech":       contact,
+			}
+			for k, v := range c.want {
+				wantBody[k] = v
+			}
+			if !reflect.DeepEqual(sent, wantBody) {
+				t.Errorf("got body %v, want %v", sent, wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package godaddy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	httpService "github.com/glucn/godaddy/internal/http"
+	"github.com/glucn/godaddy/internal/redact"
+	"github.com/vendasta/gosdks/util"
+	"net/http"
+	"strings"
+)
+
+const (
+	renewDomainPathTemplate  = "/domains/%s/renew"
+	updateDomainPathTemplate = "/domains/%s"
+
+	// defaultMinPeriod and defaultMaxPeriod are the registration limits, in years, of TLDs whose schema has none
+	defaultMinPeriod = 1
+	defaultMaxPeriod = 10
+)
+
+// OrderResult is the order GoDaddy placed for a purchase or a renewal
+type OrderResult struct {
+	OrderID   int64 `json:"orderId"`
+	ItemCount int   `json:"itemCount"`
+	Total     Money `json:"total"`
+}
+
+// orderResponse is an order as returned by GoDaddy
+type orderResponse struct {
+	OrderID   int64  `json:"orderId"`
+	ItemCount int    `json:"itemCount"`
+	Total     int64  `json:"total"`
+	Currency  string `json:"currency"`
+}
+
+func (o orderResponse) result() OrderResult {
+	return OrderResult{OrderID: o.OrderID, ItemCount: o.ItemCount, Total: NewMoney(o.Currency, o.Total)}
+}
+
+// PeriodLimits are the minimum and maximum registration period of a TLD, in years
+type PeriodLimits struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+// tldOf returns the TLD of a registered domain, ex: co.uk for example.co.uk
+func tldOf(domain string) string {
+	i := strings.Index(domain, ".")
+	if i < 0 {
+		return ""
+	}
+	return strings.ToLower(domain[i+1:])
+}
+
+func (s *Service) RenewDomain(ctx context.Context, domain string, period int) (OrderResult, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Purchase)
+	defer cancel()
+
+	if err := validateDomain(domain); err != nil {
+		return OrderResult{}, err
+	}
+	schema, err := s.schema(ctx, tldOf(domain))
+	if err != nil {
+		return OrderResult{}, err
+	}
+	limits := schema.PeriodLimits()
+	if period < limits.Min || period > limits.Max {
+		return OrderResult{}, util.Error(util.InvalidArgument, "Period of %s must be between %d and %d years", domain, limits.Min, limits.Max)
+	}
+
+	type renewBody struct {
+		Period int `json:"period"`
+	}
+
+	body := new(bytes.Buffer)
+	if err := json.NewEncoder(body).Encode(renewBody{Period: period}); err != nil {
+		redact.Errorf(ctx, "Error encoding renew body for domain %s: %v", domain, err)
+		return OrderResult{}, util.Error(util.Internal, "Error renewing domain")
+	}
+
+	url := s.endpoint.url(renewDomainPathTemplate, domain)
+	res, err := s.call(ctx, http.MethodPost, url, body, httpService.ContentTypeJSON, nil)
+	if err != nil {
+		redact.Errorf(ctx, "Error calling %s: %s", url, err.Error())
+		return OrderResult{}, convertError(err, "Error renewing domain")
+	}
+
+	order := orderResponse{}
+	if err := httpService.DecodeJSON(res, &order); err != nil {
+		redact.Errorf(ctx, "Error decoding response body of %s: %v", res.Request.URL, err)
+		return OrderResult{}, err
+	}
+	return order.result(), nil
+}
+
+func (s *Service) SetAutoRenew(ctx context.Context, domain string, renewAuto bool) error {
+	return s.updateDomain(ctx, domain, map[string]interface{}{"renewAuto": renewAuto})
+}
+
+// updateDomain patches the given fields of a domain
+func (s *Service) updateDomain(ctx context.Context, domain string, fields map[string]interface{}) error {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Domains)
+	defer cancel()
+
+	if err := validateDomain(domain); err != nil {
+		return err
+	}
+
+	body := new(bytes.Buffer)
+	if err := json.NewEncoder(body).Encode(fields); err != nil {
+		redact.Errorf(ctx, "Error encoding update body for domain %s: %v", domain, err)
+		return util.Error(util.Internal, "Error updating domain")
+	}
+
+	url := s.endpoint.url(updateDomainPathTemplate, domain)
+	res, err := s.call(ctx, http.MethodPatch, url, body, httpService.ContentTypeJSON, nil)
+	if err != nil {
+		redact.Errorf(ctx, "Error calling %s: %s", url, err.Error())
+		return convertError(err, fmt.Sprintf("Error updating domain %s", domain))
+	}
+	httpService.DiscardBody(res)
+	return nil
+}
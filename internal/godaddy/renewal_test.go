@@ -0,0 +1,95 @@
+package godaddy
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/vendasta/gosdks/util"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestRenewDomain(t *testing.T) {
+	schemas := staticSchemas{"us": parseTestSchema(t), "com": {}}
+	cases := []struct {
+		name     string
+		domain   string
+		period   int
+		rejected bool
+	}{
+		{name: "shortest period", domain: "example.us", period: 1},
+		{name: "longest period of the schema", domain: "example.us", period: 5},
+		{name: "longer than the schema allows", domain: "example.us", period: 6, rejected: true},
+		{name: "no period", domain: "example.us", period: 0, rejected: true},
+		{name: "negative period", domain: "example.us", period: -1, rejected: true},
+		{name: "longest default period", domain: "example.com", period: defaultMaxPeriod},
+		{name: "longer than the default", domain: "example.com", period: defaultMaxPeriod + 1, rejected: true},
+		{name: "invalid domain", domain: "../example.us", period: 1, rejected: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var sent map[string]interface{}
+			s := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/v1/domains/"+c.domain+"/renew" {
+					t.Errorf("got %s %s, want the domain to be renewed", r.Method, r.URL.Path)
+				}
+				json.NewDecoder(r.Body).Decode(&sent)
+				serveJSON(http.StatusOK, `{"orderId": 1234, "itemCount": 1, "total": 25980000, "currency": "cad"}`)(w, r)
+			}), WithSchemaSource(schemas))
+
+			got, err := s.RenewDomain(context.Background(), c.domain, c.period)
+			if c.rejected {
+				if util.FromError(err).ErrorType() != util.InvalidArgument || sent != nil {
+					t.Errorf("got %v and body %v, want InvalidArgument without calling GoDaddy", err, sent)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if want := (OrderResult{OrderID: 1234, ItemCount: 1, Total: NewMoney("CAD", 25980000)}); got != want {
+				t.Errorf("got %+v, want %+v", got, want)
+			}
+			if want := map[string]interface{}{"period": float64(c.period)}; !reflect.DeepEqual(sent, want) {
+				t.Errorf("got body %v, want %v", sent, want)
+			}
+		})
+	}
+}
+
+func TestRenewDomainErrors(t *testing.T) {
+	s := newTestService(t, serveJSON(http.StatusUnprocessableEntity, `{"code": "INVALID_PERIOD", "message": "The domain can't be renewed for that long"}`),
+		WithSchemaSource(staticSchemas{"us": parseTestSchema(t)}))
+	if _, err := s.RenewDomain(context.Background(), "example.us", 5); util.FromError(err).ErrorType() != util.InvalidArgument {
+		t.Errorf("got %v, want InvalidArgument", err)
+	}
+}
+
+func TestSetAutoRenew(t *testing.T) {
+	for _, renewAuto := range []bool{true, false} {
+		var sent map[string]interface{}
+		s := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPatch || r.URL.Path != "/v1/domains/example.com" {
+				t.Errorf("got %s %s, want the domain to be patched", r.Method, r.URL.Path)
+			}
+			json.NewDecoder(r.Body).Decode(&sent)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+
+		if err := s.SetAutoRenew(context.Background(), "example.com", renewAuto); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		// only renewAuto is sent, so that the other settings of the domain are left as they are
+		if want := map[string]interface{}{"renewAuto": renewAuto}; !reflect.DeepEqual(sent, want) {
+			t.Errorf("got body %v, want %v", sent, want)
+		}
+	}
+
+	s := newTestService(t, serveJSON(http.StatusNotFound, `{"code": "NOT_FOUND", "message": "Domain not found"}`))
+	if err := s.SetAutoRenew(context.Background(), "example.com", true); util.FromError(err).ErrorType() != util.NotFound {
+		t.Errorf("got %v, want NotFound", err)
+	}
+	if err := s.SetAutoRenew(context.Background(), "", true); util.FromError(err).ErrorType() != util.InvalidArgument {
+		t.Errorf("got %v, want InvalidArgument", err)
+	}
+}
@@ -0,0 +1,276 @@
+package godaddy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	httpService "github.com/glucn/godaddy/internal/http"
+	"github.com/glucn/godaddy/internal/redact"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	getPurchaseSchemaPathTemplate = "/domains/purchase/schema/%s"
+
+	// maxSchemaDepth bounds how deep Validate follows $ref, in case a schema refers to itself
+	maxSchemaDepth = 8
+)
+
+// standardPurchaseFields are the properties of every purchase schema, the others are specific to the TLD
+var standardPurchaseFields = map[string]bool{
+	"consent":           true,
+	"contactAdmin":      true,
+	"contactBilling":    true,
+	"contactRegistrant": true,
+	"contactTech":       true,
+	"domain":            true,
+	"nameServers":       true,
+	"period":            true,
+	"privacy":           true,
+	"renewAuto":         true,
+}
+
+// SchemaProperty is the JSON schema of a field of a purchase
+type SchemaProperty struct {
+	Type      string   `json:"type,omitempty"`
+	Format    string   `json:"format,omitempty"`
+	Pattern   string   `json:"pattern,omitempty"`
+	Ref       string   `json:"$ref,omitempty"`
+	Enum      []string `json:"enum,omitempty"`
+	Minimum   *float64 `json:"minimum,omitempty"`
+	Maximum   *float64 `json:"maximum,omitempty"`
+	MinLength *int     `json:"minLength,omitempty"`
+	MaxLength *int     `json:"maxLength,omitempty"`
+	MinItems  *int     `json:"minItems,omitempty"`
+	MaxItems  *int     `json:"maxItems,omitempty"`
+	// DefaultValue is used by GoDaddy when the field is missing
+	DefaultValue interface{} `json:"defaultValue,omitempty"`
+}
+
+// SchemaModel is the JSON schema of an object a purchase property refers to, ex: Contact
+type SchemaModel struct {
+	ID         string                    `json:"id"`
+	Properties map[string]SchemaProperty `json:"properties"`
+	Required   []string                  `json:"required,omitempty"`
+}
+
+// PurchaseSchema is the JSON schema GoDaddy validates the purchases of domains under a TLD against
+type PurchaseSchema struct {
+	ID         string                    `json:"id"`
+	Models     map[string]SchemaModel    `json:"models"`
+	Properties map[string]SchemaProperty `json:"properties"`
+	Required   []string                  `json:"required"`
+}
+
+// ExtraFields returns the names of the properties specific to the TLD, ex: the nexus of .us, to be set in
+// PurchaseOptions.Extra
+func (s PurchaseSchema) ExtraFields() []string {
+	extra := []string{}
+	for name := range s.Properties {
+		if !standardPurchaseFields[name] {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+	return extra
+}
+
+// PeriodLimits returns the registration period limits of the TLD, defaulting to 1 to 10 years
+func (s PurchaseSchema) PeriodLimits() PeriodLimits {
+	limits := PeriodLimits{Min: defaultMinPeriod, Max: defaultMaxPeriod}
+	period := s.Properties["period"]
+	if period.Minimum != nil && *period.Minimum > 0 {
+		limits.Min = int(*period.Minimum)
+	}
+	if period.Maximum != nil && *period.Maximum > 0 {
+		limits.Max = int(*period.Maximum)
+	}
+	return limits
+}
+
+// Validate checks a purchase body against the schema and returns the problems found, none if it is valid
+func (s PurchaseSchema) Validate(body map[string]interface{}) []FieldError {
+	problems := []FieldError{}
+	s.validateObject("", s.Properties, s.Required, body, 0, &problems)
+	return problems
+}
+
+func (s PurchaseSchema) validateObject(path string, properties map[string]SchemaProperty, required []string, object map[string]interface{}, depth int, problems *[]FieldError) {
+	for _, name := range required {
+		if isEmpty(object[name]) {
+			*problems = append(*problems, FieldError{Code: "MISSING_PROPERTY", Message: "Field is required", Path: joinPath(path, name)})
+		}
+	}
+
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value, ok := object[name]
+		if !ok || isEmpty(value) {
+			continue
+		}
+		s.validateValue(joinPath(path, name), properties[name], value, depth, problems)
+	}
+}
+
+func (s PurchaseSchema) validateValue(path string, p SchemaProperty, value interface{}, depth int, problems *[]FieldError) {
+	problem := func(code string, format string, a ...interface{}) {
+		*problems = append(*problems, FieldError{Code: code, Message: fmt.Sprintf(format, a...), Path: path})
+	}
+
+	if p.Ref != "" {
+		model, ok := s.Models[p.Ref[strings.LastIndex(p.Ref, "/")+1:]]
+		object, isObject := value.(map[string]interface{})
+		if !ok || depth >= maxSchemaDepth {
+			return
+		}
+		if !isObject {
+			problem("MISMATCH_TYPE", "Field must be an object")
+			return
+		}
+		s.validateObject(path, model.Properties, model.Required, object, depth+1, problems)
+		return
+	}
+
+	switch v := value.(type) {
+	case string:
+		if p.Type != "" && p.Type != "string" {
+			problem("MISMATCH_TYPE", "Field must be of type %s", p.Type)
+			return
+		}
+		if len(p.Enum) > 0 && !stringInSlice(v, p.Enum) {
+			problem("MISMATCH_ENUM", "Field must be one of %s", strings.Join(p.Enum, ", "))
+		}
+		if p.MinLength != nil && len(v) < *p.MinLength {
+			problem("TOO_SHORT", "Field must be at least %d characters", *p.MinLength)
+		}
+		if p.MaxLength != nil && len(v) > *p.MaxLength {
+			problem("TOO_LONG", "Field must be at most %d characters", *p.MaxLength)
+		}
+		// GoDaddy patterns are written for JavaScript, the few Go can't compile are left to GoDaddy to check
+		if re, err := regexp.Compile(p.Pattern); p.Pattern != "" && err == nil && !re.MatchString(v) {
+			problem("MISMATCH_PATTERN", "Field doesn't match the format %s", p.Pattern)
+		}
+	case float64:
+		if p.Type != "" && p.Type != "number" && p.Type != "integer" {
+			problem("MISMATCH_TYPE", "Field must be of type %s", p.Type)
+			return
+		}
+		if p.Type == "integer" && v != math.Trunc(v) {
+			problem("MISMATCH_TYPE", "Field must be an integer")
+		}
+		if p.Minimum != nil && v < *p.Minimum {
+			problem("OUT_OF_RANGE", "Field must be at least %v", *p.Minimum)
+		}
+		if p.Maximum != nil && v > *p.Maximum {
+			problem("OUT_OF_RANGE", "Field must be at most %v", *p.Maximum)
+		}
+	case bool:
+		if p.Type != "" && p.Type != "boolean" {
+			problem("MISMATCH_TYPE", "Field must be of type %s", p.Type)
+		}
+	case []interface{}:
+		if p.Type != "" && p.Type != "array" {
+			problem("MISMATCH_TYPE", "Field must be of type %s", p.Type)
+			return
+		}
+		if p.MinItems != nil && len(v) < *p.MinItems {
+			problem("TOO_SHORT", "Field must have at least %d items", *p.MinItems)
+		}
+		if p.MaxItems != nil && len(v) > *p.MaxItems {
+			problem("TOO_LONG", "Field must have at most %d items", *p.MaxItems)
+		}
+	case map[string]interface{}:
+		if p.Type != "" && p.Type != "object" {
+			problem("MISMATCH_TYPE", "Field must be of type %s", p.Type)
+		}
+	}
+}
+
+// isEmpty tells whether a JSON value is missing, null or an empty string
+func isEmpty(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	s, ok := value.(string)
+	return ok && s == ""
+}
+
+func joinPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func stringInSlice(s string, slice []string) bool {
+	for _, v := range slice {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// SchemaSource holds purchase schemas loaded ahead of time, ex: a TLDCatalog, so that purchases are checked without
+// fetching the schema of their TLD
+type SchemaSource interface {
+	// PurchaseSchema returns the purchase schema of the TLD, false if it isn't loaded
+	PurchaseSchema(tld string) (PurchaseSchema, bool)
+}
+
+// WithSchemaSource makes the service use the purchase schemas of src, fetching only the ones it doesn't have
+func WithSchemaSource(src SchemaSource) Option {
+	return func(s *Service) {
+		s.schemas = src
+	}
+}
+
+// schema returns the purchase schema of the TLD, from the schema source if it has it
+func (s *Service) schema(ctx context.Context, tld string) (PurchaseSchema, error) {
+	if s.schemas != nil {
+		if schema, ok := s.schemas.PurchaseSchema(tld); ok {
+			return schema, nil
+		}
+	}
+	return s.GetPurchaseSchema(ctx, tld)
+}
+
+func (s *Service) GetPurchaseSchema(ctx context.Context, tld string) (PurchaseSchema, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Catalog)
+	defer cancel()
+
+	url := s.endpoint.url(getPurchaseSchemaPathTemplate, tld)
+	res, err := s.call(ctx, http.MethodGet, url, nil, httpService.ContentTypeJSON, nil)
+	if err != nil {
+		redact.Errorf(ctx, "Error calling %s: %s", url, err.Error())
+		return PurchaseSchema{}, convertError(err, "Error getting purchase schema")
+	}
+
+	body := PurchaseSchema{}
+	if err := httpService.DecodeJSON(res, &body); err != nil {
+		redact.Errorf(ctx, "Error decoding response body of %s: %v", res.Request.URL, err)
+		return PurchaseSchema{}, err
+	}
+	return body, nil
+}
+
+// purchaseBodyMap returns the JSON object of a purchase body, as Validate expects it
+func purchaseBodyMap(body interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
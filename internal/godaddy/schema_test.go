@@ -0,0 +1,225 @@
+package godaddy
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/vendasta/gosdks/util"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// testPurchaseBody is a purchase body valid against testSchema
+const testPurchaseBody = `{
+  "consent": {"agreedAt": "2026-10-17T12:00:00Z", "agreedBy": "203.0.113.7", "agreementKeys": ["DNRA"]},
+  "contactRegistrant": {
+    "addressMailing": {"address1": "123 Main St", "city": "Saskatoon", "country": "CA", "postalCode": "S7S 1N5"},
+    "email": "jane.doe@example.com",
+    "nameFirst": "Jane",
+    "phone": "+1.3065551234"
+  },
+  "domain": "example.us",
+  "nexusCategory": "C11",
+  "period": 1
+}`
+
+func parseTestSchema(t *testing.T) PurchaseSchema {
+	t.Helper()
+	schema := PurchaseSchema{}
+	if err := json.Unmarshal([]byte(testSchema), &schema); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	return schema
+}
+
+func TestSchemaValidate(t *testing.T) {
+	contact := func(body map[string]interface{}) map[string]interface{} {
+		return body["contactRegistrant"].(map[string]interface{})
+	}
+	cases := []struct {
+		name   string
+		change func(body map[string]interface{})
+		want   []FieldError
+	}{
+		{
+			name:   "valid",
+			change: func(body map[string]interface{}) {},
+		},
+		{
+			name:   "required field",
+			change: func(body map[string]interface{}) { delete(body, "nexusCategory") },
+			want:   []FieldError{{Code: "MISSING_PROPERTY", Path: "nexusCategory"}},
+		},
+		{
+			name:   "required field of the contact",
+			change: func(body map[string]interface{}) { contact(body)["email"] = "" },
+			want:   []FieldError{{Code: "MISSING_PROPERTY", Path: "contactRegistrant.email"}},
+		},
+		{
+			name: "field of the address of the contact",
+			change: func(body map[string]interface{}) {
+				contact(body)["addressMailing"].(map[string]interface{})["country"] = "FR"
+			},
+			want: []FieldError{{Code: "MISMATCH_ENUM", Path: "contactRegistrant.addressMailing.country"}},
+		},
+		{
+			name:   "contact that isn't an object",
+			change: func(body map[string]interface{}) { body["contactRegistrant"] = "Jane Doe" },
+			want:   []FieldError{{Code: "MISMATCH_TYPE", Path: "contactRegistrant"}},
+		},
+		{
+			name:   "enum",
+			change: func(body map[string]interface{}) { body["nexusCategory"] = "C99" },
+			want:   []FieldError{{Code: "MISMATCH_ENUM", Path: "nexusCategory"}},
+		},
+		{
+			name:   "pattern",
+			change: func(body map[string]interface{}) { contact(body)["phone"] = "306-555-1234" },
+			want:   []FieldError{{Code: "MISMATCH_PATTERN", Path: "contactRegistrant.phone"}},
+		},
+		{
+			name:   "max length",
+			change: func(body map[string]interface{}) { contact(body)["nameFirst"] = strings.Repeat("J", 31) },
+			want:   []FieldError{{Code: "TOO_LONG", Path: "contactRegistrant.nameFirst"}},
+		},
+		{
+			name:   "minimum",
+			change: func(body map[string]interface{}) { body["period"] = float64(0) },
+			want:   []FieldError{{Code: "OUT_OF_RANGE", Path: "period"}},
+		},
+		{
+			name:   "maximum",
+			change: func(body map[string]interface{}) { body["period"] = float64(6) },
+			want:   []FieldError{{Code: "OUT_OF_RANGE", Path: "period"}},
+		},
+		{
+			name:   "integer",
+			change: func(body map[string]interface{}) { body["period"] = 1.5 },
+			want:   []FieldError{{Code: "MISMATCH_TYPE", Path: "period"}},
+		},
+		{
+			name: "min items",
+			change: func(body map[string]interface{}) {
+				body["consent"].(map[string]interface{})["agreementKeys"] = []interface{}{}
+			},
+			want: []FieldError{{Code: "TOO_SHORT", Path: "consent.agreementKeys"}},
+		},
+		{
+			name:   "type",
+			change: func(body map[string]interface{}) { body["privacy"] = "yes" },
+			want:   []FieldError{{Code: "MISMATCH_TYPE", Path: "privacy"}},
+		},
+	}
+	schema := parseTestSchema(t)
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			body := map[string]interface{}{}
+			if err := json.Unmarshal([]byte(testPurchaseBody), &body); err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			c.change(body)
+
+			got := schema.Validate(body)
+			if len(got) != len(c.want) {
+				t.Fatalf("got %+v, want %+v", got, c.want)
+			}
+			for i := range got {
+				if got[i].Code != c.want[i].Code || got[i].Path != c.want[i].Path {
+					t.Errorf("got %s at %s, want %s at %s", got[i].Code, got[i].Path, c.want[i].Code, c.want[i].Path)
+				}
+			}
+		})
+	}
+}
+
+// staticSchemas is a SchemaSource holding the schemas of some TLDs
+type staticSchemas map[string]PurchaseSchema
+
+func (s staticSchemas) PurchaseSchema(tld string) (PurchaseSchema, bool) {
+	schema, ok := s[tld]
+	return schema, ok
+}
+
+func TestPurchaseRequestProblems(t *testing.T) {
+	validConsent := Consent{AgreedAt: "2026-10-17T12:00:00Z", AgreedBy: "203.0.113.7", AgreementKeys: []string{"DNRA"}}
+	cases := []struct {
+		name      string
+		consent   Consent
+		extra     map[string]interface{}
+		want      []FieldError
+		errorType util.ErrorType
+	}{
+		{
+			name:      "unknown extra field",
+			consent:   validConsent,
+			extra:     map[string]interface{}{"nexusCategory": "C11", "legalType": "CCO"},
+			want:      []FieldError{{Code: "UNKNOWN_PROPERTY", Path: "legalType"}},
+			errorType: util.InvalidArgument,
+		},
+		{
+			name:      "standard field as an extra field",
+			consent:   validConsent,
+			extra:     map[string]interface{}{"nexusCategory": "C11", "period": 3},
+			want:      []FieldError{{Code: "UNKNOWN_PROPERTY", Path: "period"}},
+			errorType: util.InvalidArgument,
+		},
+		{
+			name:      "missing extra field",
+			consent:   validConsent,
+			want:      []FieldError{{Code: "MISSING_PROPERTY", Path: "nexusCategory"}},
+			errorType: util.InvalidArgument,
+		},
+		{
+			name:      "consent",
+			consent:   Consent{AgreedAt: "2026-10-17T12:00:00Z", AgreedBy: "203.0.113.7"},
+			extra:     map[string]interface{}{"nexusCategory": "C11"},
+			want:      []FieldError{{Code: "MISSING_PROPERTY", Path: "consent.agreementKeys"}},
+			errorType: util.FailedPrecondition,
+		},
+	}
+	s := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("got %s %s, want the purchase to be rejected without calling GoDaddy", r.Method, r.URL.Path)
+		http.NotFound(w, r)
+	}), WithSchemaSource(staticSchemas{"us": parseTestSchema(t)}))
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := s.PurchaseDomain(context.Background(), "example.us", DomainContacts{Contact: testContact}, c.consent, PurchaseOptions{Extra: c.extra})
+			validationErr, ok := err.(*ValidationError)
+			if !ok {
+				t.Fatalf("got %v, want a validation error", err)
+			}
+			if got := util.FromError(err).ErrorType(); got != c.errorType {
+				t.Errorf("got %s, want %s", got, c.errorType)
+			}
+			if len(validationErr.Fields) != len(c.want) {
+				t.Fatalf("got %+v, want %+v", validationErr.Fields, c.want)
+			}
+			for i, f := range validationErr.Fields {
+				if f.Code != c.want[i].Code || f.Path != c.want[i].Path {
+					t.Errorf("got %s at %s, want %s at %s", f.Code, f.Path, c.want[i].Code, c.want[i].Path)
+				}
+			}
+		})
+	}
+}
+
+func TestPurchaseRequestFetchesMissingSchema(t *testing.T) {
+	fetched := 0
+	s := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/domains/purchase/schema/ca" {
+			t.Errorf("got %s %s, want the schema of ca only", r.Method, r.URL.Path)
+		}
+		fetched++
+		serveJSON(http.StatusOK, testSchema)(w, r)
+	}), WithSchemaSource(staticSchemas{"us": parseTestSchema(t)}))
+
+	extra := map[string]interface{}{"nexusCategory": "C11", "legalType": "CCO"}
+	for _, domain := range []string{"example.us", "example.ca"} {
+		if _, err := s.PurchaseDomain(context.Background(), domain, DomainContacts{Contact: testContact}, Consent{}, PurchaseOptions{Extra: extra}); err == nil {
+			t.Fatalf("%s: expected a validation error", domain)
+		}
+	}
+	if fetched != 1 {
+		t.Errorf("got %d schemas fetched, want only the one missing from the source", fetched)
+	}
+}
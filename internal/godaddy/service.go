@@ -4,271 +4,212 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"fmt"
 	httpService "github.com/glucn/godaddy/internal/http"
-	"github.com/vendasta/gosdks/logging"
+	"github.com/glucn/godaddy/internal/redact"
+	"github.com/vendasta/gosdks/config"
 	"github.com/vendasta/gosdks/util"
-	"io/ioutil"
+	"io"
 	"net/http"
+	"strings"
+	"time"
 )
 
 const (
-	domainsAvailableURL          = "https://api.godaddy.com/v1/domains/available"
-	purchaseDomainURL            = "https://api.ote-godaddy.com/v1/domains/purchase"
-	domainSuggestURL             = "https://api.ote-godaddy.com/v1/domains/suggest"
-	listTLDsURL                  = "https://api.ote-godaddy.com/v1/domains/tlds"
-	getPurchaseSchemaURLTemplate = "https://api.ote-godaddy.com/v1/domains/purchase/schema/%s"
-	getPurchaseAgreementURL      = "https://api.ote-godaddy.com/v1/domains/agreements"
-	getDNSRecordsURLTemplate     = "https://api.ote-godaddy.com/v1/domains/%s/records/%s"
-	putDNSRecordURLTemplate      = "https://api.ote-godaddy.com/v1/domains/%s/records/%s/%s"
-
-	auth = ""
+	domainsAvailablePath      = "/domains/available"
+	listTLDsPath              = "/domains/tlds"
+	getDNSRecordsPathTemplate = "/domains/%s/records/%s"
+	putDNSRecordPathTemplate  = "/domains/%s/records/%s/%s"
 )
 
-var DNSTypes = []string{"A", "AAAA", "CNAME", "MX", "NS", "SOA", "SRV", "TXT"}
+// TLDType tells whether a TLD is generic, ex: com, or belongs to a country, ex: ca
+type TLDType string
 
-// Service is a service for GoDaddy APIs
-type Service struct {
-	httpClient httpService.Interface
-}
+const (
+	TLDTypeGeneric     TLDType = "GENERIC"
+	TLDTypeCountryCode TLDType = "COUNTRY_CODE"
+)
 
-// NewService returns a new implementation of the service for the service provider
-func NewService(hc httpService.Interface) Interface {
-	return &Service{
-		httpClient: hc,
-	}
+// TLD is a top level domain domains can be registered under
+type TLD struct {
+	Name string  `json:"name"`
+	Type TLDType `json:"type"`
 }
 
-func (s *Service) GetDomainAvailabilityAndPrice(ctx context.Context, domain string) (bool, int64, error) {
-	param := []httpService.URLParam{
-		{
-			Key:   "domain",
-			Value: domain,
-		},
-	}
-	res, err := s.httpClient.Call(ctx, http.MethodGet, domainsAvailableURL, nil, auth, "", param)
-
-	if err != nil {
-		logging.Errorf(ctx, "Error calling /v1/domains/available: %s", err.Error())
-		return false, 0, util.Error(util.Internal, "Error getting domain availability")
-	}
-
-	type response struct {
-		Available bool   `json:"available"`
-		Currency  string `json:"currency"`
-		Price     int64  `json:"price"`
-		//Definitive bool   `json:"definitive"`
-		//Domain     string `json:"domain"`
-		//Period     int64  `json:"period"`
-	}
-
-	body := &response{}
-	buf, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		logging.Errorf(ctx, "Error reading response body %v: %v", res, err)
-		return false, 0, util.Error(util.Internal, "Error reading response body")
-	}
-	json.Unmarshal(buf, body)
+var DNSTypes = []string{"A", "AAAA", "CNAME", "MX", "NS", "SOA", "SRV", "TXT"}
 
-	return body.Available, body.Price, nil
+// Timeouts holds the default timeout of each kind of operation, applied unless the caller's ctx expires earlier
+type Timeouts struct {
+	Availability time.Duration
+	Suggest      time.Duration
+	Purchase     time.Duration
+	Catalog      time.Duration
+	DNS          time.Duration
+	Domains      time.Duration
 }
 
-func (s *Service) PurchaseDomain(ctx context.Context, domain string, contact Contact, consent Consent) error {
-
-	type purchaseDomainBody struct {
-		Consent           Consent `json:"consent"`
-		ContactAdmin      Contact `json:"contactAdmin"`
-		ContactBilling    Contact `json:"contactBilling"`
-		ContactRegistrant Contact `json:"contactRegistrant"`
-		ContactTech       Contact `json:"contactTech"`
-		Domain            string  `json:"domain"`
-	}
-
-	bodyData := purchaseDomainBody{
-		Consent:           consent,
-		ContactAdmin:      contact,
-		ContactBilling:    contact,
-		ContactRegistrant: contact,
-		ContactTech:       contact,
-		Domain:            domain,
-	}
+// DefaultTimeouts are short for lookups and long for purchases, which GoDaddy may take a while to process
+var DefaultTimeouts = Timeouts{
+	Availability: 5 * time.Second,
+	Suggest:      10 * time.Second,
+	Purchase:     60 * time.Second,
+	Catalog:      10 * time.Second,
+	DNS:          10 * time.Second,
+	Domains:      15 * time.Second,
+}
 
-	body := new(bytes.Buffer)
-	json.NewEncoder(body).Encode(bodyData)
+// Service is a service for GoDaddy APIs
+type Service struct {
+	httpClient  httpService.Interface
+	timeouts    Timeouts
+	credentials CredentialProvider
+	endpoint    Endpoint
+	schemas     SchemaSource
+	// err is why the service is misconfigured, every call fails with it
+	err error
+
+	defaultNameservers []string
+}
 
-	_, err := s.httpClient.Call(ctx, http.MethodPost, purchaseDomainURL, body, auth, httpService.ContentTypeJSON, nil)
+// Option configures the GoDaddy service
+type Option func(*Service)
 
-	if err != nil {
-		logging.Errorf(ctx, "Error calling /v1/domains/purchase: %s", err.Error())
-		return util.Error(util.Internal, "Error purchasing domain")
+// WithTimeouts overrides the DefaultTimeouts of the GoDaddy service
+func WithTimeouts(t Timeouts) Option {
+	return func(s *Service) {
+		s.timeouts = t
 	}
-
-	return nil
 }
 
-func (s *Service) GetDomainSuggestions(ctx context.Context, domain string) ([]string, error) {
-	param := []httpService.URLParam{
-		{
-			Key:   "query",
-			Value: domain,
-		},
-		{
-			Key:   "limit",
-			Value: "10",
-		},
+// WithCredentials sets the provider of the credentials authenticating every call
+func WithCredentials(p CredentialProvider) Option {
+	return func(s *Service) {
+		s.credentials = p
 	}
+}
 
-	res, err := s.httpClient.Call(ctx, http.MethodGet, domainSuggestURL, nil, auth, "", param)
+// NewService returns a new implementation of the service for the service provider
+func NewService(hc httpService.Interface, opts ...Option) Interface {
+	s := &Service{
+		httpClient: hc,
+		timeouts:   DefaultTimeouts,
+		endpoint:   EndpointForEnv(config.CurEnv()),
 
-	if err != nil {
-		logging.Errorf(ctx, "Error calling %s: %s", domainSuggestURL, err.Error())
-		return nil, util.Error(util.Internal, "Error getting domain suggestion")
+		defaultNameservers: DefaultNameservers,
 	}
-
-	type response struct {
-		Domain string `json:"domain"`
+	for _, opt := range opts {
+		opt(s)
 	}
-
-	body := make([]response, 0)
-	buf, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		logging.Errorf(ctx, "Error reading response body %v: %v", res, err)
-		return nil, util.Error(util.Internal, "Error reading response body")
+	s.err = s.endpoint.validate()
+	if s.err == nil {
+		s.err = CheckCredentialsEndpoint(s.credentials, s.endpoint)
 	}
-	json.Unmarshal(buf, &body)
+	return s
+}
 
-	resp := make([]string, len(body))
-	for i, d := range body {
-		resp[i] = d.Domain
+// withTimeout bounds ctx by the default timeout of an operation, a zero timeout leaves ctx untouched
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
 	}
-	return resp, nil
+	return context.WithTimeout(ctx, timeout)
 }
 
-func (s *Service) GetPurchaseSchema(ctx context.Context, tld string) ([]string, error) {
-	url := fmt.Sprintf(getPurchaseSchemaURLTemplate, tld)
-	res, err := s.httpClient.Call(ctx, http.MethodGet, url, nil, auth, httpService.ContentTypeJSON, nil)
-	if err != nil {
-		logging.Errorf(ctx, "Error calling %s: %s", url, err.Error())
-		return nil, util.Error(util.Internal, "Error getting purchase schema")
+// credentialsFor returns the credentials of the tenant carried by ctx, or the default credentials
+func (s *Service) credentialsFor(ctx context.Context) (Credentials, error) {
+	if t, ok := TenantFromContext(ctx); ok && t.Credentials.valid() {
+		return t.Credentials, nil
 	}
-
-	type response struct {
-		Required []string `json:"required"`
+	if s.credentials == nil {
+		return Credentials{}, util.Error(util.Unauthenticated, "No GoDaddy credentials configured")
 	}
-
-	body := &response{}
-	buf, err := ioutil.ReadAll(res.Body)
+	c, err := s.credentials.Credentials(ctx)
 	if err != nil {
-		logging.Errorf(ctx, "Error reading response body %v: %v", res, err)
-		return nil, util.Error(util.Internal, "Error reading response body")
+		redact.Errorf(ctx, "Error getting GoDaddy credentials: %v", err)
+		return Credentials{}, util.Error(util.Unauthenticated, "Error getting GoDaddy credentials")
 	}
-	json.Unmarshal(buf, body)
-
-	return body.Required, nil
+	return c, nil
 }
 
-func (s *Service) ListTLDs(ctx context.Context) ([]string, error) {
-	res, err := s.httpClient.Call(ctx, http.MethodGet, listTLDsURL, nil, auth, "", nil)
-
-	if err != nil {
-		logging.Errorf(ctx, "Error calling %s: %s", listTLDsURL, err.Error())
-		return nil, util.Error(util.Internal, "Error listing supported TLDs")
-	}
-
-	type response struct {
-		Name string `json:"name"`
-		Type string `json:"type"`
+// call does an authenticated call to the GoDaddy API
+func (s *Service) call(ctx context.Context, method string, url string, body io.Reader, contentType string, urlParams []httpService.URLParam) (*http.Response, error) {
+	if s.err != nil {
+		return nil, s.err
 	}
-
-	body := make([]response, 0)
-	buf, err := ioutil.ReadAll(res.Body)
+	c, err := s.credentialsFor(ctx)
 	if err != nil {
-		logging.Errorf(ctx, "Error reading response body %v: %v", res, err)
-		return nil, util.Error(util.Internal, "Error reading response body")
+		return nil, err
 	}
-	json.Unmarshal(buf, &body)
-
-	resp := make([]string, len(body))
-	for i, d := range body {
-		resp[i] = d.Name
+	if t, ok := TenantFromContext(ctx); ok && t.ShopperID != "" {
+		ctx = httpService.WithHeader(ctx, HeaderShopperID, t.ShopperID)
 	}
-	return resp, nil
+	return s.httpClient.Call(ctx, method, url, body, c.Authorization(), contentType, urlParams)
 }
 
-func (s *Service) GetPurchaseAgreement(ctx context.Context, tld string) ([]string, error) {
-	param := []httpService.URLParam{
-		{
-			Key:   "tlds",
-			Value: tld,
-		},
-		{
-			Key:   "privacy",
-			Value: "false",
-		},
-	}
-	res, err := s.httpClient.Call(ctx, http.MethodGet, getPurchaseAgreementURL, nil, auth, "", param)
+func (s *Service) ListTLDs(ctx context.Context) ([]TLD, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Catalog)
+	defer cancel()
 
-	if err != nil {
-		logging.Errorf(ctx, "Error calling %s: %s", getPurchaseAgreementURL, err.Error())
-		return nil, util.Error(util.Internal, "Error getting purchase agreement")
-	}
+	url := s.endpoint.url(listTLDsPath)
+	res, err := s.call(ctx, http.MethodGet, url, nil, "", nil)
 
-	type response struct {
-		AgreementKey string `json:"agreementKey"`
-		Content      string `json:"content"`
-		Title        string `json:"title"`
-		URL          string `json:"url"`
+	if err != nil {
+		redact.Errorf(ctx, "Error calling %s: %s", url, err.Error())
+		return nil, convertError(err, "Error listing supported TLDs")
 	}
 
-	body := make([]response, 0)
-	buf, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		logging.Errorf(ctx, "Error reading response body %v: %v", res, err)
-		return nil, util.Error(util.Internal, "Error reading response body")
+	body := make([]TLD, 0)
+	if err := httpService.DecodeJSON(res, &body); err != nil {
+		redact.Errorf(ctx, "Error decoding response body of %s: %v", res.Request.URL, err)
+		return nil, err
 	}
-	json.Unmarshal(buf, &body)
 
-	resp := make([]string, len(body))
-	for i, d := range body {
-		resp[i] = d.AgreementKey
+	for i := range body {
+		body[i].Name = strings.ToLower(body[i].Name)
 	}
-	return resp, nil
+	return body, nil
 }
 
 func (s *Service) GetDNSRecords(ctx context.Context, domain string, dnsType string) ([]DNSRecord, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.DNS)
+	defer cancel()
 
-	url := fmt.Sprintf(getDNSRecordsURLTemplate, domain, dnsType)
+	url := s.endpoint.url(getDNSRecordsPathTemplate, domain, dnsType)
 
-	res, err := s.httpClient.Call(ctx, http.MethodGet, url, nil, auth, "", nil)
+	res, err := s.call(ctx, http.MethodGet, url, nil, "", nil)
 	if err != nil {
-		logging.Errorf(ctx, "Error calling %s: %s", url, err.Error())
-		return nil, util.Error(util.Internal, "Error getting DNS records")
+		redact.Errorf(ctx, "Error calling %s: %s", url, err.Error())
+		return nil, convertError(err, "Error getting DNS records")
 	}
 
 	body := make([]DNSRecord, 0)
-	buf, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		logging.Errorf(ctx, "Error reading response body %v: %v", res, err)
-		return nil, util.Error(util.Internal, "Error reading response body")
+	if err := httpService.DecodeJSON(res, &body); err != nil {
+		redact.Errorf(ctx, "Error decoding response body of %s: %v", res.Request.URL, err)
+		return nil, err
 	}
-	json.Unmarshal(buf, &body)
 
 	return body, nil
 
 }
 
 func (s *Service) PutDNSRecord(ctx context.Context, domain string, record DNSRecord) error {
-	url := fmt.Sprintf(putDNSRecordURLTemplate, domain, record.Type, record.Name)
+	ctx, cancel := withTimeout(ctx, s.timeouts.DNS)
+	defer cancel()
+
+	url := s.endpoint.url(putDNSRecordPathTemplate, domain, record.Type, record.Name)
 
 	body := new(bytes.Buffer)
-	json.NewEncoder(body).Encode([]DNSRecord{record})
+	if err := json.NewEncoder(body).Encode([]DNSRecord{record}); err != nil {
+		redact.Errorf(ctx, "Error encoding DNS record for domain %s: %v", domain, err)
+		return util.Error(util.Internal, "Error putting DNS record")
+	}
 
-	_, err := s.httpClient.Call(ctx, http.MethodPut, url, body, auth, httpService.ContentTypeJSON, nil)
+	res, err := s.call(ctx, http.MethodPut, url, body, httpService.ContentTypeJSON, nil)
 
 	if err != nil {
-		logging.Errorf(ctx, "Error calling %s: %s", url, err.Error())
-		return err
+		redact.Errorf(ctx, "Error calling %s: %s", url, err.Error())
+		return convertError(err, "Error putting DNS record")
 	}
+	httpService.DiscardBody(res)
 
 	return nil
 }
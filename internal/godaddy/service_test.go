@@ -0,0 +1,92 @@
+package godaddy
+
+import (
+	httpService "github.com/glucn/godaddy/internal/http"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// testSchema is a trimmed down purchase schema of a TLD with a nexus requirement, as GoDaddy returns it
+const testSchema = `{
+  "id": "https://api.godaddy.com/DomainPurchase#us",
+  "models": {
+    "Address": {
+      "id": "Address",
+      "properties": {
+        "address1": {"type": "string", "maxLength": 41},
+        "city": {"type": "string"},
+        "country": {"type": "string", "enum": ["CA", "US"]},
+        "postalCode": {"type": "string", "pattern": "^[A-Za-z0-9 -]{3,10}$"}
+      },
+      "required": ["address1", "city", "country"]
+    },
+    "Contact": {
+      "id": "Contact",
+      "properties": {
+        "addressMailing": {"$ref": "#/definitions/Address"},
+        "email": {"type": "string", "format": "email"},
+        "jobTitle": {"type": "string"},
+        "nameFirst": {"type": "string", "maxLength": 30},
+        "phone": {"type": "string", "pattern": "^\\+([0-9]){1,3}\\.([0-9]\\ ?){5,14}$"}
+      },
+      "required": ["addressMailing", "email", "nameFirst", "phone"]
+    },
+    "Consent": {
+      "id": "Consent",
+      "properties": {
+        "agreedAt": {"type": "string", "format": "iso-datetime"},
+        "agreedBy": {"type": "string"},
+        "agreementKeys": {"type": "array", "minItems": 1}
+      },
+      "required": ["agreedAt", "agreedBy", "agreementKeys"]
+    }
+  },
+  "properties": {
+    "consent": {"$ref": "#/definitions/Consent"},
+    "contactAdmin": {"$ref": "#/definitions/Contact"},
+    "contactBilling": {"$ref": "#/definitions/Contact"},
+    "contactRegistrant": {"$ref": "#/definitions/Contact"},
+    "contactTech": {"$ref": "#/definitions/Contact"},
+    "domain": {"type": "string"},
+    "nameServers": {"type": "array", "maxItems": 13},
+    "period": {"type": "integer", "minimum": 1, "maximum": 5},
+    "privacy": {"type": "boolean"},
+    "renewAuto": {"type": "boolean"},
+    "nexusCategory": {"type": "string", "enum": ["C11", "C12", "C21"]},
+    "appPurpose": {"type": "string"}
+  },
+  "required": ["consent", "contactRegistrant", "domain", "nexusCategory"]
+}`
+
+// testContact is a contact valid against testSchema
+var testContact = Contact{
+	AddressMailing: AddressMailing{Address1: "123 Main St", City: "Saskatoon", Country: "CA", PostalCode: "S7S 1N5", State: "SK"},
+	Email:          "jane.doe@example.com",
+	NameFirst:      "Jane",
+	NameLast:       "Doe",
+	Phone:          "+1.3065551234",
+}
+
+// newTestService returns a GoDaddy service talking to a stand-in of the GoDaddy API served by h
+func newTestService(t *testing.T, h http.Handler, opts ...Option) *Service {
+	t.Helper()
+	server := httptest.NewServer(h)
+	t.Cleanup(server.Close)
+
+	httpClient := httpService.NewService(server.Client(), httpService.WithRetryPolicy(httpService.NoRetry))
+	opts = append([]Option{
+		WithEndpoint(Endpoint{BaseURL: server.URL, APIVersion: "v1"}),
+		WithCredentials(NewStaticCredentials(Credentials{Key: "key", Secret: "secret"})),
+	}, opts...)
+	return NewService(httpClient, opts...).(*Service)
+}
+
+// serveJSON answers every request with the given status code and JSON body
+func serveJSON(statusCode int, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", httpService.ContentTypeJSON)
+		w.WriteHeader(statusCode)
+		w.Write([]byte(body))
+	}
+}
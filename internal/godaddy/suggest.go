@@ -0,0 +1,128 @@
+package godaddy
+
+import (
+	"context"
+	"fmt"
+	httpService "github.com/glucn/godaddy/internal/http"
+	"github.com/glucn/godaddy/internal/redact"
+	"github.com/vendasta/gosdks/util"
+	"github.com/vendasta/gosdks/validation"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+const (
+	domainSuggestPath = "/domains/suggest"
+
+	// DefaultSuggestLimit is the number of suggestions returned when SuggestOptions has no limit
+	DefaultSuggestLimit = 10
+)
+
+// SuggestSources are the sources GoDaddy draws suggestions from
+var SuggestSources = []string{"CC_TLD", "EXTENSION", "KEYWORD_SPIN", "PREMIUM"}
+
+// countryPattern is an ISO 3166-1 alpha-2 country code
+var countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// SuggestOptions tunes GetDomainSuggestions
+type SuggestOptions struct {
+	// Query is a domain name or keywords to suggest domains for
+	Query string
+	// Country makes suggestions relevant to a country, as an ISO 3166-1 alpha-2 code, ex: CA
+	Country string
+	// City makes suggestions relevant to a city, ex: Saskatoon
+	City string
+	// Sources restricts where suggestions come from, see SuggestSources
+	Sources []string
+	// TLDs restricts suggestions to these TLDs, ex: com
+	TLDs []string
+	// LengthMin and LengthMax bound the length of the suggested second level domains, 0 meaning no bound
+	LengthMin int
+	LengthMax int
+	// WaitMs is how long GoDaddy may take to gather suggestions, the sources that take longer are skipped
+	WaitMs int
+	// Limit is the number of suggestions, DefaultSuggestLimit if 0
+	Limit int
+}
+
+func (o SuggestOptions) validate() error {
+	rules := []validation.Rule{
+		validation.StringNotEmpty(o.Query, util.InvalidArgument, "Query must not be empty"),
+		validation.BoolTrue(o.Country == "" || countryPattern.MatchString(o.Country), util.InvalidArgument, "Country must be an ISO 3166-1 alpha-2 code, ex: CA"),
+		validation.BoolTrue(o.LengthMin >= 0 && o.LengthMax >= 0, util.InvalidArgument, "Length bounds must not be negative"),
+		validation.BoolTrue(o.LengthMax == 0 || o.LengthMin <= o.LengthMax, util.InvalidArgument, "LengthMin must not be greater than LengthMax"),
+		validation.BoolTrue(o.WaitMs >= 0, util.InvalidArgument, "WaitMs must not be negative"),
+		validation.BoolTrue(o.Limit >= 0, util.InvalidArgument, "Limit must not be negative"),
+	}
+	for _, source := range o.Sources {
+		rules = append(rules, validation.StringInSlice(source, SuggestSources, util.InvalidArgument, fmt.Sprintf("Unknown source %s", source)))
+	}
+	return validation.NewValidator().Rule(rules...).Validate()
+}
+
+func (o SuggestOptions) params() []httpService.URLParam {
+	limit := o.Limit
+	if limit == 0 {
+		limit = DefaultSuggestLimit
+	}
+	param := []httpService.URLParam{
+		{Key: "query", Value: o.Query},
+		{Key: "limit", Value: fmt.Sprintf("%d", limit)},
+	}
+	if o.Country != "" {
+		param = append(param, httpService.URLParam{Key: "country", Value: o.Country})
+	}
+	if o.City != "" {
+		param = append(param, httpService.URLParam{Key: "city", Value: o.City})
+	}
+	if len(o.Sources) > 0 {
+		param = append(param, httpService.URLParam{Key: "sources", Value: strings.Join(o.Sources, ",")})
+	}
+	if len(o.TLDs) > 0 {
+		param = append(param, httpService.URLParam{Key: "tlds", Value: strings.Join(o.TLDs, ",")})
+	}
+	if o.LengthMin > 0 {
+		param = append(param, httpService.URLParam{Key: "lengthMin", Value: fmt.Sprintf("%d", o.LengthMin)})
+	}
+	if o.LengthMax > 0 {
+		param = append(param, httpService.URLParam{Key: "lengthMax", Value: fmt.Sprintf("%d", o.LengthMax)})
+	}
+	if o.WaitMs > 0 {
+		param = append(param, httpService.URLParam{Key: "waitMs", Value: fmt.Sprintf("%d", o.WaitMs)})
+	}
+	return param
+}
+
+func (s *Service) GetDomainSuggestions(ctx context.Context, opts SuggestOptions) ([]string, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Suggest)
+	defer cancel()
+
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	url := s.endpoint.url(domainSuggestPath)
+	res, err := s.call(ctx, http.MethodGet, url, nil, "", opts.params())
+
+	if err != nil {
+		redact.Errorf(ctx, "Error calling %s: %s", url, err.Error())
+		return nil, convertError(err, "Error getting domain suggestion")
+	}
+
+	type response struct {
+		Domain string `json:"domain"`
+	}
+
+	body := make([]response, 0)
+	if err := httpService.DecodeJSON(res, &body); err != nil {
+		redact.Errorf(ctx, "Error decoding response body of %s: %v", res.Request.URL, err)
+		return nil, err
+	}
+
+	resp := make([]string, len(body))
+	for i, d := range body {
+		resp[i] = d.Domain
+	}
+	return resp, nil
+}
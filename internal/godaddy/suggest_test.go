@@ -0,0 +1,107 @@
+package godaddy
+
+import (
+	"context"
+	"github.com/vendasta/gosdks/util"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestSuggestOptionsValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		opts    SuggestOptions
+		wantErr bool
+	}{
+		{name: "query only", opts: SuggestOptions{Query: "coffee"}},
+		{name: "no query", opts: SuggestOptions{Country: "CA"}, wantErr: true},
+		{name: "country", opts: SuggestOptions{Query: "coffee", Country: "CA"}},
+		{name: "lowercase country", opts: SuggestOptions{Query: "coffee", Country: "ca"}, wantErr: true},
+		{name: "country name", opts: SuggestOptions{Query: "coffee", Country: "Canada"}, wantErr: true},
+		{name: "length bounds", opts: SuggestOptions{Query: "coffee", LengthMin: 3, LengthMax: 10}},
+		{name: "equal length bounds", opts: SuggestOptions{Query: "coffee", LengthMin: 6, LengthMax: 6}},
+		{name: "minimum length only", opts: SuggestOptions{Query: "coffee", LengthMin: 12}},
+		{name: "minimum above maximum", opts: SuggestOptions{Query: "coffee", LengthMin: 10, LengthMax: 3}, wantErr: true},
+		{name: "negative minimum length", opts: SuggestOptions{Query: "coffee", LengthMin: -1}, wantErr: true},
+		{name: "negative maximum length", opts: SuggestOptions{Query: "coffee", LengthMax: -1}, wantErr: true},
+		{name: "negative wait", opts: SuggestOptions{Query: "coffee", WaitMs: -1}, wantErr: true},
+		{name: "negative limit", opts: SuggestOptions{Query: "coffee", Limit: -1}, wantErr: true},
+		{name: "known sources", opts: SuggestOptions{Query: "coffee", Sources: SuggestSources}},
+		{name: "unknown source", opts: SuggestOptions{Query: "coffee", Sources: []string{"KEYWORD_SPIN", "SPAM"}}, wantErr: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.opts.validate()
+			if (err != nil) != c.wantErr {
+				t.Fatalf("got error %v, want error %t", err, c.wantErr)
+			}
+			if err != nil && util.FromError(err).ErrorType() != util.InvalidArgument {
+				t.Errorf("got error type %v, want InvalidArgument", util.FromError(err).ErrorType())
+			}
+		})
+	}
+}
+
+func TestSuggestOptionsParams(t *testing.T) {
+	cases := []struct {
+		name string
+		opts SuggestOptions
+		want url.Values
+	}{
+		{
+			name: "defaults",
+			opts: SuggestOptions{Query: "coffee shop"},
+			want: url.Values{"query": {"coffee shop"}, "limit": {"10"}},
+		},
+		{
+			name: "every option",
+			opts: SuggestOptions{
+				Query:     "coffee",
+				Country:   "CA",
+				City:      "Saskatoon",
+				Sources:   []string{"EXTENSION", "KEYWORD_SPIN"},
+				TLDs:      []string{"ca", "com"},
+				LengthMin: 3,
+				LengthMax: 12,
+				WaitMs:    500,
+				Limit:     25,
+			},
+			want: url.Values{
+				"query":     {"coffee"},
+				"limit":     {"25"},
+				"country":   {"CA"},
+				"city":      {"Saskatoon"},
+				"sources":   {"EXTENSION,KEYWORD_SPIN"},
+				"tlds":      {"ca,com"},
+				"lengthMin": {"3"},
+				"lengthMax": {"12"},
+				"waitMs":    {"500"},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var got url.Values
+			s := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet || r.URL.Path != "/v1/domains/suggest" {
+					t.Errorf("got %s %s, want suggestions", r.Method, r.URL.Path)
+				}
+				got = r.URL.Query()
+				serveJSON(http.StatusOK, `[{"domain": "coffee.ca"}, {"domain": "coffeeshop.com"}]`)(w, r)
+			}))
+
+			domains, err := s.GetDomainSuggestions(context.Background(), c.opts)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if want := []string{"coffee.ca", "coffeeshop.com"}; !reflect.DeepEqual(domains, want) {
+				t.Errorf("got domains %v, want %v", domains, want)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got query %v, want %v", got, c.want)
+			}
+		})
+	}
+}
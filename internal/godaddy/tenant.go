@@ -0,0 +1,81 @@
+package godaddy
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/vendasta/gosdks/util"
+	"io/ioutil"
+)
+
+// HeaderShopperID makes GoDaddy act on the account of a sub-account shopper
+const HeaderShopperID = "X-Shopper-Id"
+
+// Tenant is a partner account the service acts on behalf of
+type Tenant struct {
+	ID string `json:"id"`
+	// ShopperID is the GoDaddy sub-account receiving the purchases and DNS edits of the tenant, if any
+	ShopperID string `json:"shopperId,omitempty"`
+	// Credentials are the API credentials of the tenant, the default credentials are used when they are empty
+	Credentials Credentials `json:"credentials"`
+}
+
+type tenantKey struct{}
+
+// WithTenant returns a context making the GoDaddy calls on behalf of the tenant
+func WithTenant(ctx context.Context, t Tenant) context.Context {
+	return context.WithValue(ctx, tenantKey{}, t)
+}
+
+// TenantFromContext returns the tenant carried by ctx, if any
+func TenantFromContext(ctx context.Context) (Tenant, bool) {
+	t, ok := ctx.Value(tenantKey{}).(Tenant)
+	return t, ok
+}
+
+// TenantRegistry resolves the tenants the service acts on behalf of
+type TenantRegistry interface {
+	Tenant(ctx context.Context, id string) (Tenant, error)
+}
+
+type tenantRegistry struct {
+	tenants map[string]Tenant
+}
+
+// NewTenantRegistry returns a registry of the given tenants
+func NewTenantRegistry(tenants []Tenant) (TenantRegistry, error) {
+	r := &tenantRegistry{tenants: make(map[string]Tenant, len(tenants))}
+	for _, t := range tenants {
+		if t.ID == "" {
+			return nil, util.Error(util.InvalidArgument, "Tenant ID must not be empty")
+		}
+		if _, ok := r.tenants[t.ID]; ok {
+			return nil, util.Error(util.InvalidArgument, "Duplicated tenant %s", t.ID)
+		}
+		if (t.Credentials.Key != "" || t.Credentials.Secret != "") && !t.Credentials.valid() {
+			return nil, util.Error(util.InvalidArgument, "Tenant %s must have both a credentials key and secret", t.ID)
+		}
+		r.tenants[t.ID] = t
+	}
+	return r, nil
+}
+
+// LoadTenantRegistry returns a registry of the tenants listed in a JSON file
+func LoadTenantRegistry(path string) (TenantRegistry, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, util.Error(util.FailedPrecondition, "Error reading tenants file %s: %v", path, err)
+	}
+	tenants := make([]Tenant, 0)
+	if err := json.Unmarshal(buf, &tenants); err != nil {
+		return nil, util.Error(util.FailedPrecondition, "Error parsing tenants file %s: %v", path, err)
+	}
+	return NewTenantRegistry(tenants)
+}
+
+func (r *tenantRegistry) Tenant(ctx context.Context, id string) (Tenant, error) {
+	t, ok := r.tenants[id]
+	if !ok {
+		return Tenant{}, util.Error(util.NotFound, "Unknown tenant %s", id)
+	}
+	return t, nil
+}
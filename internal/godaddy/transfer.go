@@ -0,0 +1,184 @@
+package godaddy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	httpService "github.com/glucn/godaddy/internal/http"
+	"github.com/glucn/godaddy/internal/redact"
+	"github.com/vendasta/gosdks/util"
+	"github.com/vendasta/gosdks/validation"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+const transferDomainPathTemplate = "/domains/%s/transfer"
+
+// authCodePattern is the EPP auth code format: 6 to 32 printable characters without spaces
+var authCodePattern = regexp.MustCompile(`^[\x21-\x7E]{6,32}$`)
+
+// TransferRequest holds what GoDaddy needs to transfer a domain in from another registrar
+type TransferRequest struct {
+	AuthCode  string
+	Contacts  DomainContacts
+	Consent   Consent
+	Period    int
+	Privacy   bool
+	RenewAuto bool
+}
+
+// TransferState is the stage of a transfer
+type TransferState string
+
+const (
+	TransferPending   TransferState = "PENDING"
+	TransferCompleted TransferState = "COMPLETED"
+	TransferFailed    TransferState = "FAILED"
+	// TransferUnknown means GoDaddy reported a status unrelated to a transfer, polling should stop and the domain be
+	// checked by hand
+	TransferUnknown TransferState = "UNKNOWN"
+)
+
+// TransferFailureReason tells why a transfer failed
+type TransferFailureReason string
+
+const (
+	// TransferFailureDomainLocked means the losing registrar has a status preventing the transfer
+	TransferFailureDomainLocked TransferFailureReason = "DOMAIN_LOCKED"
+	// TransferFailureRegistryRejected means the registry refused the transfer, ex: invalid auth code
+	TransferFailureRegistryRejected TransferFailureReason = "REGISTRY_REJECTED"
+	// TransferFailureCancelled means the transfer was cancelled, ex: the owner or the losing registrar declined it
+	TransferFailureCancelled TransferFailureReason = "CANCELLED"
+	// TransferFailureUnknown is any other failure
+	TransferFailureUnknown TransferFailureReason = "UNKNOWN"
+)
+
+// TransferStatus is the progress of the transfer of a domain
+type TransferStatus struct {
+	Domain        string                `json:"domain"`
+	State         TransferState         `json:"state"`
+	FailureReason TransferFailureReason `json:"failureReason,omitempty"`
+	// UpstreamStatus is the domain status reported by GoDaddy
+	UpstreamStatus string `json:"upstreamStatus"`
+}
+
+// transferFailures maps the GoDaddy statuses of failed transfers to their reason
+var transferFailures = map[string]TransferFailureReason{
+	"FAILED_TRANSFER_IN":            TransferFailureUnknown,
+	"FAILED_TRANSFER_IN_BAD_STATUS": TransferFailureDomainLocked,
+	"FAILED_TRANSFER_IN_REGISTRY":   TransferFailureRegistryRejected,
+	"CANCELLED_TRANSFER":            TransferFailureCancelled,
+}
+
+// transferStatus converts the GoDaddy status of a domain into the status of its transfer
+func transferStatus(domain string, status string) TransferStatus {
+	s := TransferStatus{Domain: domain, UpstreamStatus: status}
+	if reason, ok := transferFailures[status]; ok {
+		s.State = TransferFailed
+		s.FailureReason = reason
+		return s
+	}
+	if status == "ACTIVE" {
+		s.State = TransferCompleted
+		return s
+	}
+	if strings.HasPrefix(status, "FAILED_") || strings.HasPrefix(status, "CANCELLED") {
+		s.State = TransferFailed
+		s.FailureReason = TransferFailureUnknown
+		return s
+	}
+	if strings.HasPrefix(status, "PENDING_") || strings.HasPrefix(status, "AWAITING_") {
+		s.State = TransferPending
+		return s
+	}
+	s.State = TransferUnknown
+	return s
+}
+
+// ValidateAuthCode checks the format of the auth code of a domain transfer
+func ValidateAuthCode(authCode string) error {
+	return validation.NewValidator().
+		Rule(validation.StringNotEmpty(authCode, util.InvalidArgument, "Auth code must not be empty")).
+		Rule(validation.BoolTrue(authCodePattern.MatchString(authCode), util.InvalidArgument, "Auth code must be 6 to 32 printable characters without spaces")).
+		Validate()
+}
+
+func (s *Service) TransferDomain(ctx context.Context, domain string, req TransferRequest) (OrderResult, error) {
+	ctx, cancel := withTimeout(ctx, s.timeouts.Purchase)
+	defer cancel()
+
+	err := validation.NewValidator().
+		Rule(validation.AtLeastOneStringRequired(req.Consent.AgreementKeys, util.FailedPrecondition, "Consent to the transfer agreements is required")).
+		Validate()
+	if err != nil {
+		return OrderResult{}, err
+	}
+	if err := validateDomain(domain); err != nil {
+		return OrderResult{}, err
+	}
+	if err := ValidateAuthCode(req.AuthCode); err != nil {
+		return OrderResult{}, err
+	}
+	roles := req.Contacts.roles()
+	if err := roles.validate(); err != nil {
+		return OrderResult{}, err
+	}
+	schema, err := s.schema(ctx, tldOf(domain))
+	if err != nil {
+		return OrderResult{}, err
+	}
+	limits := schema.PeriodLimits()
+	if req.Period < limits.Min || req.Period > limits.Max {
+		return OrderResult{}, util.Error(util.InvalidArgument, "Period of %s must be between %d and %d years", domain, limits.Min, limits.Max)
+	}
+
+	type transferBody struct {
+		roleContacts
+		AuthCode  string  `json:"authCode"`
+		Consent   Consent `json:"consent"`
+		Period    int     `json:"period"`
+		Privacy   bool    `json:"privacy"`
+		RenewAuto bool    `json:"renewAuto"`
+	}
+
+	bodyData := transferBody{
+		roleContacts: roles,
+		AuthCode:     req.AuthCode,
+		Consent:      req.Consent,
+		Period:       req.Period,
+		Privacy:      req.Privacy,
+		RenewAuto:    req.RenewAuto,
+	}
+
+	body := new(bytes.Buffer)
+	if err := json.NewEncoder(body).Encode(bodyData); err != nil {
+		redact.Errorf(ctx, "Error encoding transfer body for domain %s: %v", domain, err)
+		return OrderResult{}, util.Error(util.Internal, "Error transferring domain")
+	}
+
+	url := s.endpoint.url(transferDomainPathTemplate, domain)
+	res, err := s.call(ctx, http.MethodPost, url, body, httpService.ContentTypeJSON, nil)
+	if err != nil {
+		redact.Errorf(ctx, "Error calling %s: %s", url, err.Error())
+		return OrderResult{}, convertError(err, "Error transferring domain")
+	}
+
+	order := orderResponse{}
+	if err := httpService.DecodeJSON(res, &order); err != nil {
+		redact.Errorf(ctx, "Error decoding response body of %s: %v", res.Request.URL, err)
+		return OrderResult{}, err
+	}
+	return order.result(), nil
+}
+
+func (s *Service) GetTransferStatus(ctx context.Context, domain string) (TransferStatus, error) {
+	detail, err := s.GetDomain(ctx, domain)
+	if err != nil {
+		if apiErr, ok := err.(*APIError); ok && apiErr.ErrorType() == util.NotFound {
+			return TransferStatus{}, util.Error(util.NotFound, "No transfer found for domain %s", domain)
+		}
+		return TransferStatus{}, err
+	}
+	return transferStatus(domain, detail.Status), nil
+}
@@ -0,0 +1,71 @@
+package godaddy
+
+import (
+	"context"
+	"github.com/vendasta/gosdks/util"
+	"net/http"
+	"testing"
+)
+
+func TestTransferStatus(t *testing.T) {
+	cases := []struct {
+		status string
+		state  TransferState
+		reason TransferFailureReason
+	}{
+		{status: "ACTIVE", state: TransferCompleted},
+		{status: "PENDING_TRANSFER", state: TransferPending},
+		{status: "AWAITING_TRANSFER_IN_ACK", state: TransferPending},
+		{status: "FAILED_TRANSFER_IN_BAD_STATUS", state: TransferFailed, reason: TransferFailureDomainLocked},
+		{status: "FAILED_TRANSFER_IN_REGISTRY", state: TransferFailed, reason: TransferFailureRegistryRejected},
+		{status: "CANCELLED_TRANSFER", state: TransferFailed, reason: TransferFailureCancelled},
+		{status: "FAILED_SETUP", state: TransferFailed, reason: TransferFailureUnknown},
+		{status: "SUSPENDED", state: TransferUnknown},
+		{status: "EXPIRED", state: TransferUnknown},
+		{status: "", state: TransferUnknown},
+	}
+	for _, c := range cases {
+		got := transferStatus("example.com", c.status)
+		if got.State != c.state || got.FailureReason != c.reason || got.UpstreamStatus != c.status {
+			t.Errorf("transferStatus(%q) = %+v, want state %s and reason %q", c.status, got, c.state, c.reason)
+		}
+	}
+}
+
+func TestTransferDomainUsesPeriodLimitsOfTLD(t *testing.T) {
+	transfers := 0
+	s := newTestService(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/domains/purchase/schema/us":
+			serveJSON(http.StatusOK, testSchema)(w, r)
+		case "/v1/domains/example.us/transfer":
+			transfers++
+			serveJSON(http.StatusOK, `{"orderId":1,"itemCount":1,"total":12990000,"currency":"USD"}`)(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	req := TransferRequest{
+		AuthCode: "Xa93kdLPq2",
+		Contacts: DomainContacts{Contact: testContact},
+		Consent:  Consent{AgreementKeys: []string{"DNTA"}},
+		Period:   7,
+	}
+	_, err := s.TransferDomain(context.Background(), "example.us", req)
+	if util.FromError(err).ErrorType() != util.InvalidArgument {
+		t.Fatalf("got error %v, want InvalidArgument for a period above the maximum of the TLD", err)
+	}
+	if transfers != 0 {
+		t.Fatalf("got %d transfers, want none", transfers)
+	}
+
+	req.Period = 5
+	order, err := s.TransferDomain(context.Background(), "example.us", req)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if order.OrderID != 1 || transfers != 1 {
+		t.Errorf("got order %+v after %d transfers, want order 1", order, transfers)
+	}
+}
@@ -0,0 +1,54 @@
+package http
+
+import (
+	"encoding/json"
+	"github.com/vendasta/gosdks/util"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// MaxResponseBodySize is the largest response body read by DecodeJSON, and kept in the Error of a failed call
+const MaxResponseBodySize = 10 << 20
+
+// DecodeJSON decodes the JSON body of a successful response into v. The body is always closed, and an error is
+// returned when the response is not JSON, is larger than MaxResponseBodySize or does not decode into v.
+func DecodeJSON(resp *http.Response, v interface{}) error {
+	if resp == nil || resp.Body == nil {
+		return util.Error(util.Internal, "Empty http response")
+	}
+	defer DiscardBody(resp)
+
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || !isJSON(mediaType) {
+		return util.Error(util.Internal, "Unexpected content type %q in http response", resp.Header.Get("Content-Type"))
+	}
+
+	buf, err := ioutil.ReadAll(io.LimitReader(resp.Body, MaxResponseBodySize+1))
+	if err != nil {
+		return util.Error(util.Internal, "Error reading http response body: %v", err)
+	}
+	if len(buf) > MaxResponseBodySize {
+		return util.Error(util.Internal, "Http response body exceeds %d bytes", MaxResponseBodySize)
+	}
+	if err := json.Unmarshal(buf, v); err != nil {
+		return util.Error(util.Internal, "Error decoding http response body: %v", err)
+	}
+	return nil
+}
+
+// DiscardBody reads what is left of a response body, up to MaxResponseBodySize, and closes it so that the connection
+// can be reused
+func DiscardBody(resp *http.Response) {
+	if resp == nil || resp.Body == nil {
+		return
+	}
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, MaxResponseBodySize))
+	resp.Body.Close()
+}
+
+func isJSON(mediaType string) bool {
+	return mediaType == ContentTypeJSON || strings.HasSuffix(mediaType, "+json")
+}
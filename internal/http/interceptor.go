@@ -0,0 +1,149 @@
+package http
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/glucn/godaddy/internal/redact"
+	"github.com/vendasta/gosdks/statsd"
+	"net/http"
+	"time"
+)
+
+const (
+	// HeaderRequestID is the header carrying the request ID to the upstream
+	HeaderRequestID = "X-Request-Id"
+)
+
+// RoundTripperFunc adapts a function to an http.RoundTripper
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip calls f(req)
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Interceptor wraps the next http.RoundTripper of the chain. It runs for every attempt of a call, and must clone the
+// request before modifying it.
+type Interceptor func(next http.RoundTripper) http.RoundTripper
+
+// WithInterceptors appends interceptors to the chain of the http service. The first interceptor is the outermost one.
+func WithInterceptors(interceptors ...Interceptor) Option {
+	return func(s *Service) {
+		s.interceptors = append(s.interceptors, interceptors...)
+	}
+}
+
+// chain wraps the transport of the http client with the interceptors, leaving the given client untouched
+func chain(httpClient *http.Client, interceptors []Interceptor) *http.Client {
+	if len(interceptors) == 0 {
+		return httpClient
+	}
+	transport := httpClient.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		transport = interceptors[i](transport)
+	}
+	client := *httpClient
+	client.Transport = transport
+	return &client
+}
+
+// Logging logs every request sent to the upstream and its outcome
+func Logging() Interceptor {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			ctx := req.Context()
+			started := time.Now()
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				redact.Infof(ctx, "%s %s failed after %s: %v", req.Method, req.URL.Path, time.Since(started), err)
+				return resp, err
+			}
+			redact.Infof(ctx, "%s %s returned %d in %s", req.Method, req.URL.Path, resp.StatusCode, time.Since(started))
+			return resp, nil
+		})
+	}
+}
+
+// Auth sets the Authorization header returned by the provider on requests that don't have one
+func Auth(provider func(ctx context.Context) (string, error)) Interceptor {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("Authorization") != "" {
+				return next.RoundTrip(req)
+			}
+			authorization, err := provider(req.Context())
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Header.Set("Authorization", authorization)
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context carrying the request ID to propagate to the upstream
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, if any
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// NewRequestID returns a random request ID
+func NewRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// RequestID sets the X-Request-Id header from the request ID carried by the context, generating one if there is none
+func RequestID() Interceptor {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get(HeaderRequestID) != "" {
+				return next.RoundTrip(req)
+			}
+			requestID := RequestIDFromContext(req.Context())
+			if requestID == "" {
+				requestID = NewRequestID()
+			}
+			req = req.Clone(req.Context())
+			req.Header.Set(HeaderRequestID, requestID)
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// Timing reports the latency of every request sent to the upstream
+func Timing() Interceptor {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			started := time.Now()
+			resp, err := next.RoundTrip(req)
+			status := "error"
+			if resp != nil {
+				status = fmt.Sprintf("%d", resp.StatusCode)
+			}
+			tags := []string{
+				fmt.Sprintf("method:%s", req.Method),
+				fmt.Sprintf("host:%s", req.URL.Host),
+				fmt.Sprintf("status:%s", status),
+			}
+			statsd.Timing("http_client.latency", time.Since(started), tags, 1)
+			return resp, err
+		})
+	}
+}
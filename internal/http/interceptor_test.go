@@ -0,0 +1,175 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"github.com/glucn/godaddy/internal/redact"
+	"github.com/glucn/godaddy/internal/redact/redacttest"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// recordingInterceptor appends its name to calls when a request goes through it
+func recordingInterceptor(name string, calls *[]string) Interceptor {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			*calls = append(*calls, name)
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+// echoHeaders answers with the value of the header named by the path, ex: /X-Request-Id
+func echoHeaders(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get(strings.TrimPrefix(r.URL.Path, "/"))))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func roundTrip(t *testing.T, client *http.Client, req *http.Request) string {
+	t.Helper()
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	return string(b)
+}
+
+func TestChainOrder(t *testing.T) {
+	server := echoHeaders(t)
+	var calls []string
+	client := chain(server.Client(), []Interceptor{
+		recordingInterceptor("outer", &calls),
+		recordingInterceptor("middle", &calls),
+		recordingInterceptor("inner", &calls),
+	})
+	if client == server.Client() || server.Client().Transport == client.Transport {
+		t.Error("expected the given client to be left untouched")
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/X-Request-Id", nil)
+	roundTrip(t, client, req)
+	if got := strings.Join(calls, ","); got != "outer,middle,inner" {
+		t.Errorf("got %s, want the first interceptor to be the outermost one", got)
+	}
+	if chain(server.Client(), nil) != server.Client() {
+		t.Error("expected the client to be used as is without interceptors")
+	}
+}
+
+func TestAuth(t *testing.T) {
+	server := echoHeaders(t)
+	client := chain(server.Client(), []Interceptor{Auth(func(ctx context.Context) (string, error) {
+		return "Bearer token-1", nil
+	})})
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/Authorization", nil)
+	if got := roundTrip(t, client, req); got != "Bearer token-1" {
+		t.Errorf("got %q, want the provided authorization", got)
+	}
+	if got := req.Header.Get("Authorization"); got != "" {
+		t.Errorf("got %q set on the request of the caller, want it left untouched", got)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, server.URL+"/Authorization", nil)
+	req.Header.Set("Authorization", "sso-key k:s")
+	if got := roundTrip(t, client, req); got != "sso-key k:s" {
+		t.Errorf("got %q, want the authorization of the request to be kept", got)
+	}
+}
+
+func TestAuthProviderError(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+	client := chain(server.Client(), []Interceptor{Auth(func(ctx context.Context) (string, error) {
+		return "", errors.New("token expired")
+	})})
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	if _, err := client.Do(req); err == nil || !strings.Contains(err.Error(), "token expired") {
+		t.Errorf("got %v, want the error of the provider", err)
+	}
+	if called {
+		t.Error("expected no request to be sent without authorization")
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	server := echoHeaders(t)
+	client := chain(server.Client(), []Interceptor{RequestID()})
+
+	ctx := WithRequestID(context.Background(), "req-42")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/X-Request-Id", nil)
+	if got := roundTrip(t, client, req); got != "req-42" {
+		t.Errorf("got %q, want the request ID of ctx", got)
+	}
+	if req.Header.Get(HeaderRequestID) != "" {
+		t.Error("expected the request of the caller to be left untouched")
+	}
+
+	first := roundTrip(t, client, mustRequest(t, server.URL+"/X-Request-Id"))
+	second := roundTrip(t, client, mustRequest(t, server.URL+"/X-Request-Id"))
+	if len(first) != 32 || first == second {
+		t.Errorf("got %q and %q, want a new request ID for each request", first, second)
+	}
+
+	req = mustRequest(t, server.URL+"/X-Request-Id")
+	req.Header.Set(HeaderRequestID, "set-by-caller")
+	if got := roundTrip(t, client, req); got != "set-by-caller" {
+		t.Errorf("got %q, want the request ID of the request to be kept", got)
+	}
+}
+
+func mustRequest(t *testing.T, url string) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	return req
+}
+
+func TestLoggingIsRedacted(t *testing.T) {
+	server := echoHeaders(t)
+	out := &redacttest.Output{}
+	defer redact.SetOutput(out)()
+
+	client := chain(server.Client(), []Interceptor{Logging()})
+	roundTrip(t, client, mustRequest(t, server.URL+"/v1/shoppers/jane.doe@example.com"))
+
+	failing := chain(&http.Client{Transport: RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("dial failed with sso-key dKD3Fq7bWxMe:46Q9pXfDmyB3")
+	})}, []Interceptor{Logging()})
+	if _, err := failing.Do(mustRequest(t, server.URL+"/v1/domains/purchase")); err == nil {
+		t.Fatal("expected the call to fail")
+	}
+
+	lines := out.Lines()
+	if len(lines) != 2 {
+		t.Fatalf("got %v, want a line per request", lines)
+	}
+	if !strings.Contains(lines[0], "returned 200") || !strings.Contains(lines[1], "failed") {
+		t.Errorf("got %v, want the outcome of each request", lines)
+	}
+	for _, line := range lines {
+		for _, secret := range []string{"jane.doe@example.com", "dKD3Fq7bWxMe", "46Q9pXfDmyB3"} {
+			if strings.Contains(line, secret) {
+				t.Errorf("%q leaked into %q", secret, line)
+			}
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/vendasta/gosdks/util"
+	"sync"
+	"time"
+)
+
+// RateLimit is the number of requests a single credential may send per interval
+type RateLimit struct {
+	Requests int
+	Interval time.Duration
+}
+
+// DefaultRateLimit is the quota GoDaddy grants to each credential
+var DefaultRateLimit = RateLimit{Requests: 60, Interval: time.Minute}
+
+// Budget is a snapshot of the rate limit state of one credential
+type Budget struct {
+	// Available is the number of requests that can be sent right away
+	Available float64 `json:"available"`
+	// Waiting is the number of callers waiting for a token
+	Waiting int `json:"waiting"`
+	// Capacity is the size of the bucket
+	Capacity int `json:"capacity"`
+	// ThrottledUntil is set when the upstream returned 429 and the credential is paused
+	ThrottledUntil *time.Time `json:"throttledUntil,omitempty"`
+	// Throttled counts how many times the upstream returned 429 for this credential
+	Throttled int64 `json:"throttled"`
+}
+
+// Limiter is a client-side token bucket rate limiter keeping one bucket per credential
+type Limiter struct {
+	limit RateLimit
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens         float64
+	last           time.Time
+	throttledUntil time.Time
+	throttled      int64
+	waiting        int
+}
+
+// NewLimiter returns a new limiter applying the given rate limit to every credential
+func NewLimiter(limit RateLimit) *Limiter {
+	if limit.Requests < 1 {
+		limit.Requests = 1
+	}
+	if limit.Interval <= 0 {
+		limit.Interval = time.Second
+	}
+	return &Limiter{
+		limit:   limit,
+		buckets: map[string]*bucket{},
+	}
+}
+
+// rate is the number of tokens added per second
+func (l *Limiter) rate() float64 {
+	return float64(l.limit.Requests) / l.limit.Interval.Seconds()
+}
+
+// fingerprint identifies a credential without keeping the secret around
+func fingerprint(credential string) string {
+	sum := sha256.Sum256([]byte(credential))
+	return hex.EncodeToString(sum[:6])
+}
+
+// getBucket returns the refilled bucket of the credential, l.mu must be held
+func (l *Limiter) getBucket(credential string, now time.Time) *bucket {
+	key := fingerprint(credential)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.limit.Requests), last: now}
+		l.buckets[key] = b
+		return b
+	}
+	l.refill(b, now)
+	return b
+}
+
+// refill adds the tokens earned since the last refill, l.mu must be held
+func (l *Limiter) refill(b *bucket, now time.Time) {
+	if !now.After(b.last) {
+		return
+	}
+	b.tokens += now.Sub(b.last).Seconds() * l.rate()
+	if b.tokens > float64(l.limit.Requests) {
+		b.tokens = float64(l.limit.Requests)
+	}
+	b.last = now
+}
+
+// Wait blocks until the credential is allowed to send one request. It fails right away only when the next token
+// cannot be available before the deadline of ctx, and keeps waiting as long as it still can.
+func (l *Limiter) Wait(ctx context.Context, credential string) error {
+	l.mu.Lock()
+	b := l.getBucket(credential, time.Now())
+	b.waiting++
+	l.mu.Unlock()
+
+	defer func() {
+		l.mu.Lock()
+		b.waiting--
+		l.mu.Unlock()
+	}()
+
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		l.refill(b, now)
+		delay := l.delay(b, now)
+		if delay <= 0 {
+			b.tokens--
+			l.mu.Unlock()
+			return nil
+		}
+		l.mu.Unlock()
+
+		if deadline, ok := ctx.Deadline(); ok && now.Add(delay).After(deadline) {
+			return util.Error(util.ResourceExhausted, "Rate limit exceeded, retry in %s", delay)
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return contextError(ctx)
+		}
+	}
+}
+
+// delay returns how long to wait before the bucket holds one token, l.mu must be held
+func (l *Limiter) delay(b *bucket, now time.Time) time.Duration {
+	var delay time.Duration
+	if b.tokens < 1 {
+		delay = time.Duration((1 - b.tokens) / l.rate() * float64(time.Second))
+	}
+	if b.throttledUntil.After(now.Add(delay)) {
+		delay = b.throttledUntil.Sub(now)
+	}
+	return delay
+}
+
+// Throttle adapts to the upstream returning 429 by draining the bucket of the credential and pausing it for the given
+// duration, or for the time needed to refill one token when d is 0.
+func (l *Limiter) Throttle(credential string, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	b := l.getBucket(credential, now)
+	if d <= 0 {
+		d = time.Duration(float64(time.Second) / l.rate())
+	}
+	if b.tokens > 0 {
+		b.tokens = 0
+	}
+	if until := now.Add(d); until.After(b.throttledUntil) {
+		b.throttledUntil = until
+	}
+	b.throttled++
+}
+
+// Budget returns the current budget of the credential
+func (l *Limiter) Budget(credential string) Budget {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.budget(l.getBucket(credential, time.Now()))
+}
+
+// Budgets returns the current budget of every credential seen so far, keyed by a fingerprint of the credential
+func (l *Limiter) Budgets() map[string]Budget {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	budgets := make(map[string]Budget, len(l.buckets))
+	for key, b := range l.buckets {
+		l.refill(b, now)
+		budgets[key] = l.budget(b)
+	}
+	return budgets
+}
+
+func (l *Limiter) budget(b *bucket) Budget {
+	budget := Budget{
+		Available: b.tokens,
+		Waiting:   b.waiting,
+		Capacity:  l.limit.Requests,
+		Throttled: b.throttled,
+	}
+	if b.throttledUntil.After(time.Now()) {
+		until := b.throttledUntil
+		budget.ThrottledUntil = &until
+	}
+	return budget
+}
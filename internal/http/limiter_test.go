@@ -0,0 +1,103 @@
+package http
+
+import (
+	"context"
+	"github.com/vendasta/gosdks/util"
+	"testing"
+	"time"
+)
+
+func TestLimiterAllowsBurstUpToCapacity(t *testing.T) {
+	l := NewLimiter(RateLimit{Requests: 3, Interval: time.Minute})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(ctx, "key"); err != nil {
+			t.Fatalf("request %d: unexpected error %v", i+1, err)
+		}
+	}
+	if err := l.Wait(ctx, "key"); util.FromError(err).ErrorType() != util.ResourceExhausted {
+		t.Fatalf("got error %v, want ResourceExhausted once the bucket is empty", err)
+	}
+	if err := l.Wait(ctx, "other-key"); err != nil {
+		t.Fatalf("unexpected error %v, every credential has its own bucket", err)
+	}
+}
+
+func TestLimiterWaitsForReachableToken(t *testing.T) {
+	l := NewLimiter(RateLimit{Requests: 1, Interval: 50 * time.Millisecond})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := l.Wait(ctx, "key"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	start := time.Now()
+	if err := l.Wait(ctx, "key"); err != nil {
+		t.Fatalf("unexpected error %v, the next token is reachable before the deadline", err)
+	}
+	if waited := time.Since(start); waited < 40*time.Millisecond {
+		t.Errorf("waited %s, want about one refill interval", waited)
+	}
+}
+
+func TestLimiterWaitWithoutDeadline(t *testing.T) {
+	l := NewLimiter(RateLimit{Requests: 1, Interval: 20 * time.Millisecond})
+
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(context.Background(), "key"); err != nil {
+			t.Fatalf("request %d: unexpected error %v", i+1, err)
+		}
+	}
+}
+
+func TestLimiterWaitIsCancelled(t *testing.T) {
+	l := NewLimiter(RateLimit{Requests: 1, Interval: time.Hour})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	if err := l.Wait(ctx, "key"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if err := l.Wait(ctx, "key"); util.FromError(err).ErrorType() != util.Aborted {
+		t.Fatalf("got error %v, want Aborted", err)
+	}
+	if waiting := l.Budget("key").Waiting; waiting != 0 {
+		t.Errorf("got %d waiting callers, want 0", waiting)
+	}
+}
+
+func TestLimiterThrottle(t *testing.T) {
+	l := NewLimiter(RateLimit{Requests: 10, Interval: time.Second})
+	l.Throttle("key", time.Minute)
+
+	budget := l.Budget("key")
+	if budget.Available >= 1 {
+		t.Errorf("got %v available tokens, want the bucket to be drained", budget.Available)
+	}
+	if budget.ThrottledUntil == nil || budget.Throttled != 1 {
+		t.Errorf("got budget %+v, want the credential to be throttled once", budget)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := l.Wait(ctx, "key"); util.FromError(err).ErrorType() != util.ResourceExhausted {
+		t.Fatalf("got error %v, want ResourceExhausted while throttled", err)
+	}
+}
+
+func TestLimiterRefillsUpToCapacity(t *testing.T) {
+	l := NewLimiter(RateLimit{Requests: 2, Interval: 20 * time.Millisecond})
+	if err := l.Wait(context.Background(), "key"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	if available := l.Budget("key").Available; available != 2 {
+		t.Errorf("got %v available tokens, want the capacity", available)
+	}
+	if budgets := l.Budgets(); len(budgets) != 1 {
+		t.Errorf("got %d budgets, want 1", len(budgets))
+	}
+}
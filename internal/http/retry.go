@@ -0,0 +1,147 @@
+package http
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy configures how Service.Call retries a failed attempt
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one. Values below 1 are treated as 1.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between two attempts when no Retry-After is given
+	MaxBackoff time.Duration
+	// MaxRetryAfter caps the delay honoured from a Retry-After header, MaxBackoff is used when it is 0
+	MaxRetryAfter time.Duration
+	// Multiplier grows the backoff after every attempt
+	Multiplier float64
+	// Jitter is the fraction (0 to 1) of the backoff that is randomized
+	Jitter float64
+}
+
+var (
+	// DefaultRetryPolicy retries transient failures up to 3 times with exponential backoff
+	DefaultRetryPolicy = RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		MaxRetryAfter:  30 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+
+	// NoRetry makes exactly one attempt
+	NoRetry = RetryPolicy{MaxAttempts: 1}
+)
+
+type retryNonIdempotentKey struct{}
+
+// WithRetryNonIdempotent returns a context that allows Service.Call to retry non-idempotent methods (ex: POST, PATCH).
+// Only opt in when the upstream endpoint is known to be safe to repeat.
+func WithRetryNonIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryNonIdempotentKey{}, true)
+}
+
+func retryNonIdempotent(ctx context.Context) bool {
+	allowed, _ := ctx.Value(retryNonIdempotentKey{}).(bool)
+	return allowed
+}
+
+func isIdempotent(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func isRetryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func (p RetryPolicy) maxAttempts() int {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// backoff returns the delay before the given retry, attempt being the number of attempts already made
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	delay := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		jitter := math.Min(p.Jitter, 1)
+		delay = delay * (1 - jitter + 2*jitter*rand.Float64())
+	}
+	return time.Duration(delay)
+}
+
+// retryAfter returns the delay asked by the Retry-After header of resp, capped to MaxRetryAfter
+func (p RetryPolicy) retryAfter(resp *http.Response) time.Duration {
+	d := parseRetryAfter(resp)
+	max := p.MaxRetryAfter
+	if max <= 0 {
+		max = p.MaxBackoff
+	}
+	if max > 0 && d > max {
+		return max
+	}
+	return d
+}
+
+// parseRetryAfter parses the Retry-After header, which is either a number of seconds or an HTTP date
+func parseRetryAfter(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+	value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if d := time.Until(date); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// sleep waits for the given duration, returning early with the context error when ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
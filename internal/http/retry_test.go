@@ -0,0 +1,126 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2}
+
+	for attempt, want := range map[int]time.Duration{
+		1: 100 * time.Millisecond,
+		2: 200 * time.Millisecond,
+		3: 400 * time.Millisecond,
+		4: 800 * time.Millisecond,
+		5: time.Second,
+		9: time.Second,
+	} {
+		if got := p.backoff(attempt); got != want {
+			t.Errorf("backoff(%d) = %s, want %s", attempt, got, want)
+		}
+	}
+}
+
+func TestBackoffJitter(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: time.Second, Multiplier: 2, Jitter: 0.2}
+
+	for i := 0; i < 100; i++ {
+		if got := p.backoff(1); got < 800*time.Millisecond || got > 1200*time.Millisecond {
+			t.Fatalf("backoff(1) = %s, want within 20%% of 1s", got)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	cases := []struct {
+		name  string
+		value string
+		min   time.Duration
+		max   time.Duration
+	}{
+		{name: "missing", value: "", min: 0, max: 0},
+		{name: "seconds", value: "7", min: 7 * time.Second, max: 7 * time.Second},
+		{name: "negative seconds", value: "-3", min: 0, max: 0},
+		{name: "invalid", value: "soon", min: 0, max: 0},
+		{name: "http date", value: time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat), min: 8 * time.Second, max: 10 * time.Second},
+		{name: "http date in the past", value: time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat), min: 0, max: 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}}
+			if c.value != "" {
+				resp.Header.Set("Retry-After", c.value)
+			}
+			if got := parseRetryAfter(resp); got < c.min || got > c.max {
+				t.Errorf("parseRetryAfter(%q) = %s, want between %s and %s", c.value, got, c.min, c.max)
+			}
+		})
+	}
+}
+
+func TestRetryAfterIsCapped(t *testing.T) {
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"3600"}}}
+
+	if got := (RetryPolicy{MaxBackoff: 5 * time.Second, MaxRetryAfter: 30 * time.Second}).retryAfter(resp); got != 30*time.Second {
+		t.Errorf("retryAfter = %s, want MaxRetryAfter", got)
+	}
+	if got := (RetryPolicy{MaxBackoff: 5 * time.Second}).retryAfter(resp); got != 5*time.Second {
+		t.Errorf("retryAfter = %s, want MaxBackoff when MaxRetryAfter is not set", got)
+	}
+}
+
+func TestCallRetriesOnlyIdempotentRequests(t *testing.T) {
+	cases := []struct {
+		name     string
+		method   string
+		ctx      context.Context
+		attempts int32
+	}{
+		{name: "GET is retried", method: http.MethodGet, ctx: context.Background(), attempts: 3},
+		{name: "PUT is retried", method: http.MethodPut, ctx: context.Background(), attempts: 3},
+		{name: "POST is not retried", method: http.MethodPost, ctx: context.Background(), attempts: 1},
+		{name: "PATCH is not retried", method: http.MethodPatch, ctx: context.Background(), attempts: 1},
+		{name: "POST is retried when allowed", method: http.MethodPost, ctx: WithRetryNonIdempotent(context.Background()), attempts: 3},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var attempts int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&attempts, 1)
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer server.Close()
+
+			s := NewService(server.Client(), WithRetryPolicy(RetryPolicy{MaxAttempts: 3}))
+			if _, err := s.Call(c.ctx, c.method, server.URL, nil, "", "", nil); err == nil {
+				t.Fatal("expected the call to fail")
+			}
+			if attempts != c.attempts {
+				t.Errorf("got %d attempts, want %d", attempts, c.attempts)
+			}
+		})
+	}
+}
+
+func TestCallDoesNotRetryClientErrors(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}))
+	defer server.Close()
+
+	s := NewService(server.Client(), WithRetryPolicy(RetryPolicy{MaxAttempts: 3}))
+	_, err := s.Call(context.Background(), http.MethodGet, server.URL, nil, "", "", nil)
+	if httpErr, ok := err.(*Error); !ok || httpErr.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("got error %v, want the 422 response", err)
+	}
+	if attempts != 1 {
+		t.Errorf("got %d attempts, want 1", attempts)
+	}
+}
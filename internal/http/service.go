@@ -1,20 +1,36 @@
 package http
 
 import (
+	"bytes"
 	"context"
 	"fmt"
-	"github.com/vendasta/gosdks/logging"
+	"github.com/glucn/godaddy/internal/redact"
+	"github.com/vendasta/gosdks/statsd"
 	"github.com/vendasta/gosdks/util"
 	"github.com/vendasta/gosdks/validation"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"time"
 )
 
 // Service is an http
 type Service struct {
-	httpClient *http.Client
+	httpClient   *http.Client
+	retryPolicy  RetryPolicy
+	limiter      *Limiter
+	interceptors []Interceptor
+}
+
+// Option configures the http service
+type Option func(*Service)
+
+// WithRetryPolicy overrides the DefaultRetryPolicy of the http service
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(s *Service) {
+		s.retryPolicy = p
+	}
 }
 
 // URLParam holds a parameter of HTTP call
@@ -35,6 +51,10 @@ const (
 type Error struct {
 	Body       string
 	StatusCode int
+	// RawBody is the unmodified response body
+	RawBody []byte
+	// Header is the header of the response
+	Header http.Header
 }
 
 func (e *Error) Error() string {
@@ -44,11 +64,25 @@ func (e *Error) Error() string {
 	return e.Body
 }
 
+// WithRateLimiter makes the http service wait for the limiter before every attempt, using the authorization of the call
+// as the credential
+func WithRateLimiter(l *Limiter) Option {
+	return func(s *Service) {
+		s.limiter = l
+	}
+}
+
 // NewService returns a new implementation of the http service
-func NewService(httpClient *http.Client) Interface {
-	return &Service{
-		httpClient: httpClient,
+func NewService(httpClient *http.Client, opts ...Option) Interface {
+	s := &Service{
+		httpClient:  httpClient,
+		retryPolicy: DefaultRetryPolicy,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.httpClient = chain(s.httpClient, s.interceptors)
+	return s
 }
 
 // Call will do an http call given a valid http method (ex: GET, POST, PUT...)
@@ -58,17 +92,122 @@ func (s *Service) Call(ctx context.Context, method string, url string, body io.R
 		Rule(validation.StringNotEmpty(method, util.InvalidArgument, "Method must not be empty")).
 		Validate()
 	if err != nil {
-		logging.Errorf(ctx, "Failed validation when doing http call: %v", err)
+		redact.Errorf(ctx, "Failed validation when doing http call: %v", err)
 		return nil, err
 	}
 
-	req, err := http.NewRequest(strings.ToUpper(method), url, body)
+	var payload []byte
+	if body != nil {
+		payload, err = ioutil.ReadAll(body)
+		if err != nil {
+			redact.Errorf(ctx, "Error reading body of %s http request with url %s: %v", method, url, err)
+			return nil, util.Error(util.Internal, "Error reading http request body")
+		}
+	}
+
+	canRetry := isIdempotent(method) || retryNonIdempotent(ctx)
+	maxAttempts := s.retryPolicy.maxAttempts()
+
+	for attempt := 1; ; attempt++ {
+		req, err := newRequest(ctx, method, url, payload, authorization, contentType, urlParams)
+		if err != nil {
+			redact.Errorf(ctx, "Error creating %s http request with url %s: %v", method, url, err)
+			return nil, util.Error(util.Internal, "Error getting http request")
+		}
+
+		if s.limiter != nil {
+			if err := s.limiter.Wait(ctx, authorization); err != nil {
+				redact.Errorf(ctx, "Rate limit wait before attempt %d of %s http request to %s failed: %v", attempt, method, req.URL, err)
+				return nil, err
+			}
+		}
+
+		redact.Debugf(ctx, "Attempt %d of %s http request to %s", attempt, method, req.URL)
+		resp, err := s.httpClient.Do(req)
+		countAttempt(req, resp, attempt)
+
+		if err != nil && ctx.Err() != nil {
+			redact.Errorf(ctx, "Context done during %s http request to %s: %v", method, req.URL, ctx.Err())
+			return nil, contextError(ctx)
+		}
+
+		if err == nil && resp.StatusCode <= 299 {
+			return resp, nil
+		}
+		if s.limiter != nil && err == nil && resp.StatusCode == http.StatusTooManyRequests {
+			s.limiter.Throttle(authorization, s.retryPolicy.retryAfter(resp))
+		}
+
+		retryable := err != nil || isRetryableStatus(resp.StatusCode)
+		if !retryable || !canRetry || attempt >= maxAttempts {
+			if err != nil {
+				redact.Errorf(ctx, "Error doing %s http request with request %s: %v", method, redact.Request(req), err)
+				return nil, util.Error(util.Internal, "Error during http call")
+			}
+			redact.Errorf(ctx, "Error doing %s http request with request %s: status %d", method, redact.Request(req), resp.StatusCode)
+			return nil, parseError(resp)
+		}
+
+		delay := s.retryPolicy.retryAfter(resp)
+		if delay == 0 {
+			delay = s.retryPolicy.backoff(attempt)
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+			redact.Errorf(ctx, "Giving up %s http request to %s after %d attempts, next retry in %s exceeds the deadline", method, req.URL, attempt, delay)
+			if err != nil {
+				return nil, util.Error(util.DeadlineExceeded, "Error during http call")
+			}
+			return nil, parseError(resp)
+		}
+
+		if err != nil {
+			redact.Warningf(ctx, "Attempt %d of %s http request to %s failed: %v, retrying in %s", attempt, method, req.URL, err, delay)
+		} else {
+			redact.Warningf(ctx, "Attempt %d of %s http request to %s returned status %d, retrying in %s", attempt, method, req.URL, resp.StatusCode, delay)
+			DiscardBody(resp)
+		}
+
+		if err := sleep(ctx, delay); err != nil {
+			redact.Errorf(ctx, "Context done while waiting to retry %s http request to %s: %v", method, req.URL, err)
+			return nil, contextError(ctx)
+		}
+	}
+}
+
+type headersKey struct{}
+
+// WithHeader returns a context adding a header to the calls made with it, ex: to act on behalf of a sub-account
+func WithHeader(ctx context.Context, key string, value string) context.Context {
+	headers := http.Header{}
+	if parent, ok := ctx.Value(headersKey{}).(http.Header); ok {
+		for k, v := range parent {
+			headers[k] = v
+		}
+	}
+	headers.Set(key, value)
+	return context.WithValue(ctx, headersKey{}, headers)
+}
+
+func newRequest(ctx context.Context, method string, url string, payload []byte, authorization string, contentType string, urlParams []URLParam) (*http.Request, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, strings.ToUpper(method), url, body)
 	if err != nil {
-		logging.Errorf(ctx, "Error creating %s http request with url %s, body %v: %v", method, url, body, err)
-		return nil, util.Error(util.Internal, "Error getting http request")
+		return nil, err
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if headers, ok := ctx.Value(headersKey{}).(http.Header); ok {
+		for key, values := range headers {
+			req.Header[key] = values
+		}
 	}
-	req.Header.Set("Authorization", authorization)
-	req.Header.Set("Content-Type", contentType)
 
 	if urlParams != nil && len(urlParams) > 0 {
 		q := req.URL.Query()
@@ -78,32 +217,46 @@ func (s *Service) Call(ctx context.Context, method string, url string, body io.R
 		}
 		req.URL.RawQuery = q.Encode()
 	}
+	return req, nil
+}
 
-	resp, err := s.httpClient.Do(req)
-	if err != nil {
-		logging.Errorf(ctx, "Error doing %s http request with request %v: %v", method, req, err)
-		return nil, util.Error(util.Internal, "Error during http call")
+// contextError converts the error of a done context into a service error
+func contextError(ctx context.Context) error {
+	if ctx.Err() == context.Canceled {
+		return util.Error(util.Aborted, "Http call cancelled")
 	}
+	return util.Error(util.DeadlineExceeded, "Http call deadline exceeded")
+}
 
-	if resp.StatusCode > 299 {
-		logging.Errorf(ctx, "Error doing %s http request with request %v: %v", method, req, err)
-		return nil, parseError(resp)
+// countAttempt counts every attempt so that retry storms show up in the metrics
+func countAttempt(req *http.Request, resp *http.Response, attempt int) {
+	status := "error"
+	if resp != nil {
+		status = fmt.Sprintf("%d", resp.StatusCode)
 	}
-
-	return resp, nil
+	tags := []string{
+		fmt.Sprintf("method:%s", req.Method),
+		fmt.Sprintf("host:%s", req.URL.Host),
+		fmt.Sprintf("status:%s", status),
+		fmt.Sprintf("retry:%t", attempt > 1),
+	}
+	statsd.Incr("http_client.attempt", tags, 1)
 }
 
 func parseError(r *http.Response) error {
-	body := ""
+	var bodyBytes []byte
 	if r.Body != nil {
 		defer r.Body.Close()
-		bodyBytes, err := ioutil.ReadAll(r.Body)
+		var err error
+		bodyBytes, err = ioutil.ReadAll(io.LimitReader(r.Body, MaxResponseBodySize+1))
 		if err != nil {
 			return err
 		}
-		body = string(bodyBytes)
+		if len(bodyBytes) > MaxResponseBodySize {
+			bodyBytes = bodyBytes[:MaxResponseBodySize]
+		}
 	}
 
-	errorBody := fmt.Sprintf("%s: %s", http.StatusText(r.StatusCode), body)
-	return &Error{StatusCode: r.StatusCode, Body: errorBody}
+	errorBody := fmt.Sprintf("%s: %s", http.StatusText(r.StatusCode), string(bodyBytes))
+	return &Error{StatusCode: r.StatusCode, Body: errorBody, RawBody: bodyBytes, Header: r.Header}
 }
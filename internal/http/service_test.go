@@ -0,0 +1,71 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/glucn/godaddy/internal/redact"
+	"github.com/glucn/godaddy/internal/redact/redacttest"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCallNeverLogsSecrets(t *testing.T) {
+	const (
+		authorization = "sso-key dKD3Fq7bWxMe_46Q8SYxpVqDY6K7kv3VJwB:46Q9pXfDmyB3r6Z7WdKLu3"
+		contact       = `{"contactRegistrant":{"email":"jane.doe@example.com","nameFirst":"Jane","phone":"+1.3065551234"}}`
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprint(w, contact)
+	}))
+	defer server.Close()
+
+	out := &redacttest.Output{}
+	defer redact.SetOutput(out)()
+
+	policy := DefaultRetryPolicy
+	policy.InitialBackoff = 0
+	policy.MaxAttempts = 2
+	s := NewService(server.Client(), WithRetryPolicy(policy), WithInterceptors(Logging()))
+
+	_, err := s.Call(context.Background(), http.MethodPut, server.URL+"/v1/domains/purchase", strings.NewReader(contact),
+		authorization, ContentTypeJSON, []URLParam{{Key: "authCode", Value: "Xa93kdLPq2"}})
+	if err == nil {
+		t.Fatal("expected the call to fail")
+	}
+	lines := out.Lines()
+	if len(lines) == 0 {
+		t.Fatal("expected the failed call to be logged")
+	}
+
+	for _, line := range lines {
+		for _, secret := range []string{"dKD3Fq7bWxMe_46Q8SYxpVqDY6K7kv3VJwB", "46Q9pXfDmyB3r6Z7WdKLu3", "jane.doe@example.com", "+1.3065551234", "Xa93kdLPq2"} {
+			if strings.Contains(line, secret) {
+				t.Errorf("%q leaked into %q", secret, line)
+			}
+		}
+	}
+}
+
+func TestCallBoundsErrorBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(bytes.Repeat([]byte("x"), MaxResponseBodySize+1024))
+	}))
+	defer server.Close()
+
+	s := NewService(server.Client(), WithRetryPolicy(NoRetry))
+	_, err := s.Call(context.Background(), http.MethodGet, server.URL, nil, "", "", nil)
+	httpErr, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("got error %v, want *Error", err)
+	}
+	if len(httpErr.RawBody) != MaxResponseBodySize {
+		t.Errorf("got a %d bytes body, want it truncated to %d bytes", len(httpErr.RawBody), MaxResponseBodySize)
+	}
+}
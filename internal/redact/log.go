@@ -0,0 +1,64 @@
+package redact
+
+import (
+	"context"
+	"fmt"
+	"github.com/vendasta/gosdks/logging"
+)
+
+// Output receives log lines once they have been redacted
+type Output interface {
+	Debugf(ctx context.Context, f string, a ...interface{})
+	Infof(ctx context.Context, f string, a ...interface{})
+	Warningf(ctx context.Context, f string, a ...interface{})
+	Errorf(ctx context.Context, f string, a ...interface{})
+}
+
+type gosdksOutput struct{}
+
+func (gosdksOutput) Debugf(ctx context.Context, f string, a ...interface{}) {
+	logging.Debugf(ctx, f, a...)
+}
+
+func (gosdksOutput) Infof(ctx context.Context, f string, a ...interface{}) {
+	logging.Infof(ctx, f, a...)
+}
+
+func (gosdksOutput) Warningf(ctx context.Context, f string, a ...interface{}) {
+	logging.Warningf(ctx, f, a...)
+}
+
+func (gosdksOutput) Errorf(ctx context.Context, f string, a ...interface{}) {
+	logging.Errorf(ctx, f, a...)
+}
+
+var output Output = gosdksOutput{}
+
+// SetOutput replaces the destination of redacted log lines and returns a function restoring the previous one
+func SetOutput(o Output) func() {
+	previous := output
+	output = o
+	return func() {
+		output = previous
+	}
+}
+
+// Debugf emits a redacted debug log
+func Debugf(ctx context.Context, f string, a ...interface{}) {
+	output.Debugf(ctx, "%s", String(fmt.Sprintf(f, a...)))
+}
+
+// Infof emits a redacted info log
+func Infof(ctx context.Context, f string, a ...interface{}) {
+	output.Infof(ctx, "%s", String(fmt.Sprintf(f, a...)))
+}
+
+// Warningf emits a redacted warning log
+func Warningf(ctx context.Context, f string, a ...interface{}) {
+	output.Warningf(ctx, "%s", String(fmt.Sprintf(f, a...)))
+}
+
+// Errorf emits a redacted error log
+func Errorf(ctx context.Context, f string, a ...interface{}) {
+	output.Errorf(ctx, "%s", String(fmt.Sprintf(f, a...)))
+}
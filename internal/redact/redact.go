@@ -0,0 +1,108 @@
+package redact
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Mask replaces every redacted value
+const Mask = "[REDACTED]"
+
+// sensitiveHeaders hold credentials and are never logged
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+	"X-Api-Key":           true,
+}
+
+// sensitiveFields are the JSON fields and struct fields holding secrets, contact PII and transfer auth codes
+var sensitiveFields = []string{
+	"address1", "address2", "agreedBy", "apiKey", "apiSecret", "authCode", "city", "email", "fax", "jobTitle",
+	"nameFirst", "nameLast", "nameMiddle", "organization", "password", "phone", "postalCode", "secret", "state",
+}
+
+var (
+	credentialPattern = regexp.MustCompile(`(?i)\b(sso-key|bearer|basic)\s+[^\s"',\]}]+`)
+	jsonFieldPattern  = regexp.MustCompile(`(?i)"(` + strings.Join(sensitiveFields, "|") + `)"\s*:\s*"(?:[^"\\]|\\.)*"`)
+	// structFieldPattern matches the name of struct fields printed with %+v, ex: {Email:jane@example.com}
+	structFieldPattern = regexp.MustCompile(`(?i)\b(` + strings.Join(sensitiveFields, "|") + `):`)
+	// structNextFieldPattern matches the start of the next field of a struct printed with %+v
+	structNextFieldPattern = regexp.MustCompile(`\s[A-Za-z_]\w*:`)
+	queryParamPattern      = regexp.MustCompile(`(?i)\b(` + strings.Join(sensitiveFields, "|") + `)=[^&\s"]*`)
+	emailPattern           = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+)
+
+// String masks credentials, contact PII and auth codes found in s
+func String(s string) string {
+	s = credentialPattern.ReplaceAllString(s, "$1 "+Mask)
+	s = jsonFieldPattern.ReplaceAllString(s, `"$1":"`+Mask+`"`)
+	s = maskStructFields(s)
+	s = queryParamPattern.ReplaceAllString(s, "$1="+Mask)
+	return emailPattern.ReplaceAllString(s, Mask)
+}
+
+// maskStructFields masks the values of sensitive struct fields printed with %+v. A value may hold spaces, ex:
+// {Address1:123 Main St City:Saskatoon}, so it runs up to the next field or the end of the struct.
+func maskStructFields(s string) string {
+	var b strings.Builder
+	for {
+		loc := structFieldPattern.FindStringIndex(s)
+		if loc == nil {
+			b.WriteString(s)
+			return b.String()
+		}
+		b.WriteString(s[:loc[1]])
+		b.WriteString(Mask)
+
+		rest := s[loc[1]:]
+		end := len(rest)
+		if i := strings.IndexByte(rest, '}'); i >= 0 {
+			end = i
+		}
+		if next := structNextFieldPattern.FindStringIndex(rest[:end]); next != nil {
+			end = next[0]
+		}
+		s = rest[end:]
+	}
+}
+
+// Header returns a copy of h with the values of sensitive headers masked
+func Header(h http.Header) http.Header {
+	redacted := make(http.Header, len(h))
+	for key, values := range h {
+		if sensitiveHeaders[http.CanonicalHeaderKey(key)] {
+			redacted[key] = []string{Mask}
+			continue
+		}
+		masked := make([]string, len(values))
+		for i, v := range values {
+			masked[i] = String(v)
+		}
+		redacted[key] = masked
+	}
+	return redacted
+}
+
+// Request describes an outbound request, with its method, URL and headers, safe for logging
+func Request(req *http.Request) string {
+	if req == nil {
+		return "<nil>"
+	}
+	header := Header(req.Header)
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = fmt.Sprintf("%s: %s", key, strings.Join(header[key], ", "))
+	}
+	return fmt.Sprintf("%s %s [%s]", req.Method, String(req.URL.String()), strings.Join(parts, "; "))
+}
@@ -0,0 +1,129 @@
+package redact
+
+import (
+	"context"
+	"github.com/glucn/godaddy/internal/redact/redacttest"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const (
+	apiKey    = "dKD3Fq7bWxMe_46Q8SYxpVqDY6K7kv3VJwB"
+	apiSecret = "46Q9pXfDmyB3r6Z7WdKLu3"
+	authCode  = "Xa!93kd_LPq2"
+)
+
+var secrets = []string{
+	apiKey, apiSecret, authCode,
+	"jane.doe@example.com", "+1.3065551234", "Jane", "Doe", "123 Main St", "Suite 400", "S7S1N5", "203.0.113.7",
+	"Saskatoon", "SK", "Acme Widgets",
+}
+
+const contactJSON = `{"contactRegistrant":{"addressMailing":{"address1":"123 Main St","address2":"Suite 400",` +
+	`"city":"Saskatoon","country":"CA","postalCode":"S7S1N5","state":"SK"},"email":"jane.doe@example.com",` +
+	`"nameFirst":"Jane","nameLast":"Doe","organization":"Acme Widgets","phone":"+1.3065551234"},"consent":{"agreedBy":"203.0.113.7"},` +
+	`"authCode":"` + authCode + `"}`
+
+func assertNoSecret(t *testing.T, s string) {
+	t.Helper()
+	for _, secret := range secrets {
+		if strings.Contains(s, secret) {
+			t.Errorf("%q leaked into %q", secret, s)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	cases := map[string]string{
+		"sso-key authorization": "Authorization: sso-key " + apiKey + ":" + apiSecret,
+		"contact json":          contactJSON,
+		"query parameter":       "https://api.godaddy.com/v1/domains/example.com/transfer?authCode=" + authCode,
+		"email in message":      "The email jane.doe@example.com is invalid",
+	}
+	for name, s := range cases {
+		t.Run(name, func(t *testing.T) {
+			redacted := String(s)
+			if !strings.Contains(redacted, Mask) {
+				t.Errorf("expected %q to contain %s", redacted, Mask)
+			}
+			assertNoSecret(t, redacted)
+		})
+	}
+}
+
+func TestStringStructDump(t *testing.T) {
+	redacted := String("{Email:jane.doe@example.com NameFirst:Jane NameLast:Doe Phone:+1.3065551234}")
+	expected := "{Email:[REDACTED] NameFirst:[REDACTED] NameLast:[REDACTED] Phone:[REDACTED]}"
+	if redacted != expected {
+		t.Errorf("expected %q, got %q", expected, redacted)
+	}
+}
+
+func TestStringStructDumpWithSpaces(t *testing.T) {
+	redacted := String("{AddressMailing:{Address1:123 Main St Address2:Suite 400 City:Saskatoon Country:CA PostalCode:S7S 1N5 State:SK} Organization:Acme Widgets Inc}")
+	expected := "{AddressMailing:{Address1:[REDACTED] Address2:[REDACTED] City:[REDACTED] Country:CA PostalCode:[REDACTED] State:[REDACTED]} Organization:[REDACTED]}"
+	if redacted != expected {
+		t.Errorf("expected %q, got %q", expected, redacted)
+	}
+}
+
+func TestStringKeepsNonSensitiveData(t *testing.T) {
+	s := `{"domain":"example.com","country":"CA","available":true}`
+	if redacted := String(s); redacted != s {
+		t.Errorf("expected %q to be left untouched, got %q", s, redacted)
+	}
+}
+
+func TestHeader(t *testing.T) {
+	h := http.Header{}
+	h.Set("Authorization", "sso-key "+apiKey+":"+apiSecret)
+	h.Set("Cookie", "session="+apiSecret)
+	h.Set("X-Shopper-Id", "12345")
+
+	redacted := Header(h)
+	if got := redacted.Get("Authorization"); got != Mask {
+		t.Errorf("expected Authorization to be masked, got %q", got)
+	}
+	if got := redacted.Get("Cookie"); got != Mask {
+		t.Errorf("expected Cookie to be masked, got %q", got)
+	}
+	if got := redacted.Get("X-Shopper-Id"); got != "12345" {
+		t.Errorf("expected X-Shopper-Id to be kept, got %q", got)
+	}
+	if h.Get("Authorization") == Mask {
+		t.Error("expected the original header to be left untouched")
+	}
+}
+
+func TestRequest(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "https://api.godaddy.com/v1/domains/purchase", strings.NewReader(contactJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "sso-key "+apiKey+":"+apiSecret)
+
+	assertNoSecret(t, Request(req))
+}
+
+func TestLogsNeverContainSecrets(t *testing.T) {
+	out := &redacttest.Output{}
+	defer SetOutput(out)()
+
+	ctx := context.Background()
+	req, _ := http.NewRequest(http.MethodPost, "https://api.godaddy.com/v1/domains/purchase", nil)
+	req.Header.Set("Authorization", "sso-key "+apiKey+":"+apiSecret)
+
+	Errorf(ctx, "Error doing request %v: %s", req.Header, contactJSON)
+	Warningf(ctx, "Retrying with authorization %s", "sso-key "+apiKey+":"+apiSecret)
+	Infof(ctx, "Transfer of %s with %s", "example.com", "authCode="+authCode)
+	Debugf(ctx, "Contact %s", "jane.doe@example.com")
+
+	lines := out.Lines()
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 log lines, got %d", len(lines))
+	}
+	for _, line := range lines {
+		assertNoSecret(t, line)
+	}
+}
@@ -0,0 +1,40 @@
+// Package redacttest captures the log lines emitted through the redact package, so that tests can check what would
+// have been logged. Install it with redact.SetOutput.
+package redacttest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Output records every log line it receives
+type Output struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (o *Output) capture(f string, a ...interface{}) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.lines = append(o.lines, fmt.Sprintf(f, a...))
+}
+
+// Lines returns the log lines captured so far
+func (o *Output) Lines() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]string(nil), o.lines...)
+}
+
+// Debugf captures a debug log
+func (o *Output) Debugf(_ context.Context, f string, a ...interface{}) { o.capture(f, a...) }
+
+// Infof captures an info log
+func (o *Output) Infof(_ context.Context, f string, a ...interface{}) { o.capture(f, a...) }
+
+// Warningf captures a warning log
+func (o *Output) Warningf(_ context.Context, f string, a ...interface{}) { o.capture(f, a...) }
+
+// Errorf captures an error log
+func (o *Output) Errorf(_ context.Context, f string, a ...interface{}) { o.capture(f, a...) }
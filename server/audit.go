@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/glucn/godaddy/internal/audit"
+	"github.com/glucn/godaddy/internal/godaddy"
+	httpService "github.com/glucn/godaddy/internal/http"
+	"github.com/vendasta/gosdks/config"
+	"github.com/vendasta/gosdks/logging"
+	"github.com/vendasta/gosdks/util"
+	"golang.org/x/oauth2/google"
+	"net/http"
+	"os"
+	"time"
+)
+
+// openAuditStore opens the consent audit trail. It is kept in the Cloud Storage bucket GODADDY_AUDIT_BUCKET, shared by
+// every replica, which is required in production. Outside of production it may be a local GODADDY_AUDIT_FILE instead.
+func openAuditStore(ctx context.Context, env config.Env) (audit.Store, error) {
+	bucket := os.Getenv("GODADDY_AUDIT_BUCKET")
+	if bucket == "" {
+		if env == config.Prod || env == config.Demo {
+			return nil, util.Error(util.FailedPrecondition, "GODADDY_AUDIT_BUCKET must be set in %s", env.Name())
+		}
+		auditFile := os.Getenv("GODADDY_AUDIT_FILE")
+		if auditFile == "" {
+			auditFile = defaultAuditFile
+		}
+		return audit.NewFileStore(auditFile)
+	}
+
+	prefix := os.Getenv("GODADDY_AUDIT_PREFIX")
+	if prefix == "" {
+		prefix = defaultAuditPrefix
+	}
+	tokenSource, err := google.DefaultTokenSource(ctx, audit.GCSScope)
+	if err != nil {
+		return nil, util.Error(util.FailedPrecondition, "Error loading Google credentials: %s", err.Error())
+	}
+	// the GoDaddy rate limit doesn't apply to Cloud Storage, so the trail has its own client
+	client := httpService.NewService(
+		&http.Client{Timeout: httpClientTimeout},
+		httpService.WithInterceptors(
+			httpService.RequestID(),
+			httpService.Auth(func(ctx context.Context) (string, error) {
+				token, err := tokenSource.Token()
+				if err != nil {
+					return "", err
+				}
+				return token.Type() + " " + token.AccessToken, nil
+			}),
+		),
+	)
+	return audit.NewGCSStore(ctx, client, bucket, prefix)
+}
+
+// registerAuditHandlers registers the endpoints querying the consent audit trail
+func registerAuditHandlers(mux *http.ServeMux, store audit.Store) {
+	// consent-records answers the records as a JSON list, or as a JSON lines file to download with format jsonl
+	mux.HandleFunc("/consent-records", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		type request struct {
+			Domain string    `json:"domain"`
+			From   time.Time `json:"from"`
+			To     time.Time `json:"to"`
+			Format string    `json:"format"`
+		}
+		req := request{}
+		if !readJSON(ctx, w, r, &req, "{domain: string, from: RFC 3339 time, to: RFC 3339 time, format: json|jsonl}") {
+			return
+		}
+		if req.Format != "" && req.Format != "json" && req.Format != "jsonl" {
+			writeError(ctx, w, util.Error(util.InvalidArgument, "Format must be json or jsonl"))
+			return
+		}
+		if !req.From.IsZero() && !req.To.IsZero() && !req.From.Before(req.To) {
+			writeError(ctx, w, util.Error(util.InvalidArgument, "From must be before to"))
+			return
+		}
+
+		// the records are those of the tenant the request is made on behalf of, only principals acting for every tenant
+		// may query them all
+		filter := audit.Filter{Domain: req.Domain, From: req.From, To: req.To}
+		if tenant, ok := godaddy.TenantFromContext(ctx); ok {
+			filter.Tenant = tenant.ID
+		} else if caller, ok := principalFromContext(ctx); !ok || !caller.AllTenants {
+			writeError(ctx, w, util.Error(util.PermissionDenied, "Consent records must be queried on behalf of a tenant, set %s", headerTenantID))
+			return
+		}
+
+		records, err := store.Query(ctx, filter)
+		if err != nil {
+			logging.Errorf(ctx, "Error querying consent records: %s", err.Error())
+			writeError(ctx, w, err)
+			return
+		}
+
+		if req.Format != "jsonl" {
+			type response struct {
+				Records []audit.Record `json:"records"`
+			}
+			writeJSON(ctx, w, http.StatusOK, response{Records: records})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", `attachment; filename="consent-records.jsonl"`)
+		w.WriteHeader(http.StatusOK)
+		encoder := json.NewEncoder(w)
+		for _, record := range records {
+			if err := encoder.Encode(record); err != nil {
+				logging.Errorf(ctx, "Error exporting consent records: %s", err.Error())
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/glucn/godaddy/internal/audit"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestConsentRecordsAreScopedToTenant(t *testing.T) {
+	store, err := audit.NewFileStore(filepath.Join(t.TempDir(), "consent-audit.jsonl"))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer store.Close()
+	for _, r := range []audit.Record{
+		{Kind: audit.KindConsent, Domain: "acme.com", Tenant: "acme", AgreedBy: "203.0.113.7"},
+		{Kind: audit.KindConsent, Domain: "globex.com", Tenant: "globex", AgreedBy: "198.51.100.1"},
+		{Kind: audit.KindConsent, Domain: "default.com", AgreedBy: "192.0.2.1"},
+	} {
+		if _, err := store.Append(context.Background(), r); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+	principals, err := newPrincipalSet([]principal{
+		{ID: "acme-backend", TokenSHA256: tokenHash("acme-token"), Tenants: []string{"acme"}, DefaultIdentity: true},
+		{ID: "ops", TokenSHA256: tokenHash("ops-token"), AllTenants: true, DefaultIdentity: true},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	mux := http.NewServeMux()
+	registerAuditHandlers(mux, store)
+	h := withPrincipal(principals, withTenant(testTenants(t), mux))
+
+	cases := []struct {
+		name       string
+		token      string
+		tenantID   string
+		body       string
+		statusCode int
+		domains    []string
+	}{
+		{name: "records of the tenant", token: "acme-token", tenantID: "acme", body: `{}`, statusCode: http.StatusOK, domains: []string{"acme.com"}},
+		{name: "records of another tenant by domain", token: "acme-token", tenantID: "acme", body: `{"domain": "globex.com"}`, statusCode: http.StatusOK, domains: []string{}},
+		{name: "on behalf of another tenant", token: "acme-token", tenantID: "globex", body: `{}`, statusCode: http.StatusForbidden},
+		{name: "unscoped", token: "acme-token", body: `{}`, statusCode: http.StatusForbidden},
+		{name: "unscoped for every tenant", token: "ops-token", body: `{}`, statusCode: http.StatusOK, domains: []string{"acme.com", "globex.com", "default.com"}},
+		{name: "tenant for every tenant", token: "ops-token", tenantID: "globex", body: `{}`, statusCode: http.StatusOK, domains: []string{"globex.com"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/consent-records", bytes.NewBufferString(c.body))
+			r.Header.Set("Authorization", "Bearer "+c.token)
+			if c.tenantID != "" {
+				r.Header.Set(headerTenantID, c.tenantID)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != c.statusCode {
+				t.Fatalf("got status %d, want %d: %s", w.Code, c.statusCode, w.Body.String())
+			}
+			if c.statusCode != http.StatusOK {
+				return
+			}
+			body := struct {
+				Records []audit.Record `json:"records"`
+			}{}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if len(body.Records) != len(c.domains) {
+				t.Fatalf("got %d records, want %v", len(body.Records), c.domains)
+			}
+			for i, r := range body.Records {
+				if r.Domain != c.domains[i] {
+					t.Errorf("record %d: got %s, want %s", i, r.Domain, c.domains[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/vendasta/gosdks/logging"
+	"github.com/vendasta/gosdks/util"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// principal is an authenticated caller of the server, ex: a partner backend
+type principal struct {
+	ID string `json:"id"`
+	// TokenSHA256 is the hex encoded SHA-256 of the bearer token of the principal, the token itself is never stored
+	TokenSHA256 string `json:"tokenSha256"`
+	// Tenants are the IDs of the tenants the principal may act on behalf of
+	Tenants []string `json:"tenants,omitempty"`
+	// AllTenants lets the principal act on behalf of any tenant
+	AllTenants bool `json:"allTenants,omitempty"`
+	// DefaultIdentity lets the principal make requests without a tenant, with the default GoDaddy identity
+	DefaultIdentity bool `json:"defaultIdentity,omitempty"`
+	// ActsForUsers lets the principal name the user it acts for in X-User-Id, recorded in the consent audit trail
+	ActsForUsers bool `json:"actsForUsers,omitempty"`
+
+	tokenHash []byte
+}
+
+// anonymousPrincipal makes every request when no principals are configured, which is only allowed outside of
+// production
+var anonymousPrincipal = &principal{ID: "anonymous", AllTenants: true, DefaultIdentity: true, ActsForUsers: true}
+
+// mayActFor tells whether the principal may act on behalf of the tenant
+func (p *principal) mayActFor(tenantID string) bool {
+	if p.AllTenants {
+		return true
+	}
+	for _, t := range p.Tenants {
+		if t == tenantID {
+			return true
+		}
+	}
+	return false
+}
+
+// principalSet authenticates the callers of the server by their bearer token
+type principalSet struct {
+	principals []*principal
+}
+
+// newPrincipalSet returns the given principals, checking that each has an ID and a SHA-256 token hash
+func newPrincipalSet(principals []principal) (*principalSet, error) {
+	ps := &principalSet{principals: make([]*principal, 0, len(principals))}
+	ids := map[string]bool{}
+	for i := range principals {
+		p := principals[i]
+		if p.ID == "" {
+			return nil, util.Error(util.InvalidArgument, "Principal ID must not be empty")
+		}
+		if ids[p.ID] {
+			return nil, util.Error(util.InvalidArgument, "Duplicated principal %s", p.ID)
+		}
+		hash, err := hex.DecodeString(p.TokenSHA256)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, util.Error(util.InvalidArgument, "Principal %s must have the hex encoded SHA-256 of its token", p.ID)
+		}
+		p.tokenHash = hash
+		ids[p.ID] = true
+		ps.principals = append(ps.principals, &p)
+	}
+	return ps, nil
+}
+
+// loadPrincipalSet returns the principals listed in a JSON file
+func loadPrincipalSet(path string) (*principalSet, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, util.Error(util.FailedPrecondition, "Error reading principals file %s: %v", path, err)
+	}
+	principals := make([]principal, 0)
+	if err := json.Unmarshal(buf, &principals); err != nil {
+		return nil, util.Error(util.FailedPrecondition, "Error parsing principals file %s: %v", path, err)
+	}
+	return newPrincipalSet(principals)
+}
+
+// authenticate returns the principal owning the token. Every principal is compared in constant time so that the
+// time taken does not tell how close the token is to a valid one.
+func (ps *principalSet) authenticate(token string) (*principal, bool) {
+	sum := sha256.Sum256([]byte(token))
+	var found *principal
+	for _, p := range ps.principals {
+		if subtle.ConstantTimeCompare(sum[:], p.tokenHash) == 1 {
+			found = p
+		}
+	}
+	return found, found != nil
+}
+
+type principalKey struct{}
+
+// principalFromContext returns the authenticated principal of the inbound request carried by ctx
+func principalFromContext(ctx context.Context) (*principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*principal)
+	return p, ok
+}
+
+const bearerPrefix = "Bearer "
+
+// unauthenticatedPaths are served without a principal, ex: to the health checks of the load balancer
+var unauthenticatedPaths = map[string]bool{
+	"/healthz": true,
+}
+
+// withPrincipal authenticates the inbound request with its bearer token. Every request is made by the anonymous
+// principal when principals is nil.
+func withPrincipal(principals *principalSet, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if unauthenticatedPaths[r.URL.Path] {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		caller := anonymousPrincipal
+		if principals != nil {
+			authorization := r.Header.Get("Authorization")
+			if len(authorization) < len(bearerPrefix) || !strings.EqualFold(authorization[:len(bearerPrefix)], bearerPrefix) {
+				writeError(ctx, w, util.Error(util.Unauthenticated, "Missing bearer token"))
+				return
+			}
+			var ok bool
+			caller, ok = principals.authenticate(strings.TrimSpace(authorization[len(bearerPrefix):]))
+			if !ok {
+				logging.Warningf(ctx, "Rejected request to %s with an unknown bearer token", r.URL.Path)
+				writeError(ctx, w, util.Error(util.Unauthenticated, "Invalid bearer token"))
+				return
+			}
+		}
+		h.ServeHTTP(w, r.WithContext(context.WithValue(ctx, principalKey{}, caller)))
+	})
+}
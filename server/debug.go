@@ -0,0 +1,24 @@
+package main
+
+import (
+	httpService "github.com/glucn/godaddy/internal/http"
+	"github.com/vendasta/gosdks/util"
+	"net/http"
+)
+
+// registerDebugHandlers registers the endpoints inspecting the state of the service. They span every tenant, so they
+// are only served to the principals acting on behalf of all of them.
+func registerDebugHandlers(mux *http.ServeMux, limiter *httpService.Limiter) {
+	mux.HandleFunc("/debug/rate-limit", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if caller, ok := principalFromContext(ctx); !ok || !caller.AllTenants {
+			writeError(ctx, w, util.Error(util.PermissionDenied, "Debug endpoints are restricted to principals acting for every tenant"))
+			return
+		}
+
+		type response struct {
+			Budgets map[string]httpService.Budget `json:"budgets"`
+		}
+		writeJSON(ctx, w, http.StatusOK, response{Budgets: limiter.Budgets()})
+	})
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	httpService "github.com/glucn/godaddy/internal/http"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDebugRateLimitIsRestricted(t *testing.T) {
+	principals, err := newPrincipalSet([]principal{
+		{ID: "acme-backend", TokenSHA256: tokenHash("acme-token"), Tenants: []string{"acme"}},
+		{ID: "default-backend", TokenSHA256: tokenHash("default-token"), DefaultIdentity: true},
+		{ID: "ops", TokenSHA256: tokenHash("ops-token"), AllTenants: true},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	mux := http.NewServeMux()
+	registerDebugHandlers(mux, httpService.NewLimiter(httpService.DefaultRateLimit))
+
+	cases := []struct {
+		name       string
+		principals *principalSet
+		token      string
+		statusCode int
+	}{
+		{name: "principal of a tenant", principals: principals, token: "acme-token", statusCode: http.StatusForbidden},
+		{name: "principal of the default identity", principals: principals, token: "default-token", statusCode: http.StatusForbidden},
+		{name: "principal of every tenant", principals: principals, token: "ops-token", statusCode: http.StatusOK},
+		{name: "anonymous without principals", statusCode: http.StatusOK},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/debug/rate-limit", nil)
+			if c.token != "" {
+				r.Header.Set("Authorization", "Bearer "+c.token)
+			}
+			w := httptest.NewRecorder()
+			withPrincipal(c.principals, mux).ServeHTTP(w, r)
+			if w.Code != c.statusCode {
+				t.Errorf("got status %d, want %d: %s", w.Code, c.statusCode, w.Body.String())
+			}
+		})
+	}
+}
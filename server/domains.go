@@ -0,0 +1,130 @@
+package main
+
+import (
+	"github.com/glucn/godaddy/internal/godaddy"
+	"github.com/vendasta/gosdks/logging"
+	"github.com/vendasta/gosdks/util"
+	"net/http"
+)
+
+// registerDomainHandlers registers the endpoints managing the domains owned by the account
+func registerDomainHandlers(mux *http.ServeMux, godaddyService godaddy.Interface) {
+	mux.HandleFunc("/list-domains", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		type request struct {
+			Statuses     []string `json:"statuses"`
+			StatusGroups []string `json:"statusGroups"`
+			Limit        int      `json:"limit"`
+			Marker       string   `json:"marker"`
+			Includes     []string `json:"includes"`
+		}
+		req := request{}
+		if !readJSON(ctx, w, r, &req, "{statuses: [string], statusGroups: [string], limit: int, marker: string, includes: [string]}") {
+			return
+		}
+
+		page, err := godaddyService.ListDomains(ctx, godaddy.ListDomainsOptions{
+			Statuses:     req.Statuses,
+			StatusGroups: req.StatusGroups,
+			Limit:        req.Limit,
+			Marker:       req.Marker,
+			Includes:     req.Includes,
+		})
+		if err != nil {
+			logging.Errorf(ctx, "Error listing domains: %s", err.Error())
+			writeError(ctx, w, err)
+			return
+		}
+
+		writeJSON(ctx, w, http.StatusOK, page)
+	})
+
+	mux.HandleFunc("/get-domain", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		type request struct {
+			Domain string `json:"domain"`
+		}
+		req := request{}
+		if !readJSON(ctx, w, r, &req, "{domain: string}") {
+			return
+		}
+
+		domain, err := godaddyService.GetDomain(ctx, req.Domain)
+		if err != nil {
+			logging.Errorf(ctx, "Error getting domain %s: %s", req.Domain, err.Error())
+			writeError(ctx, w, err)
+			return
+		}
+
+		writeJSON(ctx, w, http.StatusOK, domain)
+	})
+
+	mux.HandleFunc("/renew-domain", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		type request struct {
+			Domain string `json:"domain"`
+			Period int    `json:"period"`
+		}
+		req := request{}
+		if !readJSON(ctx, w, r, &req, "{domain: string, period: int}") {
+			return
+		}
+
+		orderCtx, cancel := detach(ctx)
+		defer cancel()
+		order, err := godaddyService.RenewDomain(orderCtx, req.Domain, req.Period)
+		if err != nil {
+			logging.Errorf(ctx, "Error renewing domain %s for %d years: %s", req.Domain, req.Period, err.Error())
+			writeError(ctx, w, err)
+			return
+		}
+
+		writeJSON(ctx, w, http.StatusOK, order)
+	})
+
+	mux.HandleFunc("/set-auto-renew", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		type request struct {
+			Domain    string `json:"domain"`
+			RenewAuto *bool  `json:"renewAuto"`
+		}
+		req := request{}
+		if !readJSON(ctx, w, r, &req, "{domain: string, renewAuto: bool}") {
+			return
+		}
+		if req.RenewAuto == nil {
+			writeError(ctx, w, util.Error(util.InvalidArgument, "renewAuto is required"))
+			return
+		}
+
+		err := godaddyService.SetAutoRenew(ctx, req.Domain, *req.RenewAuto)
+		if err != nil {
+			logging.Errorf(ctx, "Error setting auto-renew of domain %s: %s", req.Domain, err.Error())
+			writeError(ctx, w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	mux.HandleFunc("/update-domain-contacts", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		type request struct {
+			Domain   string                 `json:"domain"`
+			Contacts godaddy.DomainContacts `json:"contacts"`
+		}
+		req := request{}
+		if !readJSON(ctx, w, r, &req, "{domain: string, contacts: {contact: object, registrant: object, admin: object, tech: object, billing: object}}") {
+			return
+		}
+
+		err := godaddyService.UpdateDomainContacts(ctx, req.Domain, req.Contacts)
+		if err != nil {
+			logging.Errorf(ctx, "Error updating contacts of domain %s: %s", req.Domain, err.Error())
+			writeError(ctx, w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+}
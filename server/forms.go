@@ -0,0 +1,44 @@
+package main
+
+import (
+	"github.com/glucn/godaddy/internal/godaddy"
+	"github.com/vendasta/gosdks/logging"
+	"github.com/vendasta/gosdks/util"
+	"net/http"
+	"strings"
+)
+
+// registerFormHandlers registers the endpoints describing the forms the frontend shows to end-users
+func registerFormHandlers(mux *http.ServeMux, catalog *godaddy.TLDCatalog) {
+	// purchase-form answers the fields and agreements of a purchase under a TLD, so that the frontend needs no change
+	// when a TLD is added. It is served from the catalog, only the details of a TLD not loaded yet are fetched.
+	mux.HandleFunc("/purchase-form", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		type request struct {
+			TLD     string `json:"tld"`
+			Privacy bool   `json:"privacy"`
+		}
+		req := request{}
+		if !readJSON(ctx, w, r, &req, "{tld: string, privacy: bool}") {
+			return
+		}
+		tld := strings.ToLower(strings.TrimPrefix(req.TLD, "."))
+		if tld == "" {
+			writeError(ctx, w, util.Error(util.InvalidArgument, "TLD must not be empty"))
+			return
+		}
+		if catalog.LoadedAt().IsZero() {
+			writeError(ctx, w, util.Error(util.Unavailable, "The TLD catalog is loading"))
+			return
+		}
+
+		entry, err := catalog.Details(ctx, tld)
+		if err != nil {
+			logging.Errorf(ctx, "Error getting purchase form of TLD %s: %s", tld, err.Error())
+			writeError(ctx, w, err)
+			return
+		}
+
+		writeJSON(ctx, w, http.StatusOK, godaddy.NewPurchaseForm(tld, entry.Schema, entry.Agreements(req.Privacy)))
+	})
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/glucn/godaddy/internal/godaddy"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPurchaseForm(t *testing.T) {
+	mux := http.NewServeMux()
+	catalog := godaddy.NewTLDCatalog(fakeTLDService{}, godaddy.WithCatalogPace(0))
+	registerFormHandlers(mux, catalog)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/purchase-form", bytes.NewBufferString(body)))
+		return w
+	}
+
+	if w := post(`{"tld": "ca"}`); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("got status %d, want 503 until the catalog is loaded", w.Code)
+	}
+	if err := catalog.Refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	cases := []struct {
+		name       string
+		body       string
+		statusCode int
+	}{
+		{name: "empty tld", body: `{"privacy": true}`, statusCode: http.StatusBadRequest},
+		{name: "tld not sold", body: `{"tld": "org"}`, statusCode: http.StatusNotFound},
+		{name: "tld of the catalog", body: `{"tld": ".CA"}`, statusCode: http.StatusOK},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			w := post(c.body)
+			if w.Code != c.statusCode {
+				t.Fatalf("got status %d, want %d: %s", w.Code, c.statusCode, w.Body.String())
+			}
+			if c.statusCode != http.StatusOK {
+				return
+			}
+			form := godaddy.PurchaseForm{}
+			if err := json.Unmarshal(w.Body.Bytes(), &form); err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if form.TLD != "ca" || len(form.Agreements) != 1 || len(form.Fields) != 0 {
+				t.Errorf("got %+v, want the form of ca", form)
+			}
+		})
+	}
+}
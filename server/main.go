@@ -7,25 +7,119 @@ import (
 	"encoding/json"
 	"github.com/glucn/godaddy/internal/godaddy"
 	httpService "github.com/glucn/godaddy/internal/http"
+	"github.com/vendasta/gosdks/config"
 	"github.com/vendasta/gosdks/logging"
-	"golang.org/x/sync/errgroup"
-	"sync"
 	"github.com/vendasta/gosdks/serverconfig"
+	"github.com/vendasta/gosdks/util"
+	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
+	"os"
+	"strings"
+	"sync"
+	"time"
 )
 
 const (
 	APP_NAME = "godaddy"
 	httpPort = 11001
+
+	// httpClientTimeout is a last resort, the GoDaddy service bounds each operation with a shorter timeout
+	httpClientTimeout = 2 * time.Minute
+
+	// defaultAuditFile is where the consent audit trail is appended unless GODADDY_AUDIT_FILE is set
+	defaultAuditFile = "/var/lib/godaddy/consent-audit.jsonl"
+
+	// defaultAuditPrefix is where the consent audit trail is kept in GODADDY_AUDIT_BUCKET unless GODADDY_AUDIT_PREFIX
+	// is set
+	defaultAuditPrefix = "consent-audit/"
+
+	// catalogRefreshInterval is how often the TLD catalog is reloaded, TLDs and their agreements rarely change
+	catalogRefreshInterval = 6 * time.Hour
 )
 
 func main() {
 	ctx := context.Background()
-	//env := config.CurEnv()
+	env := config.CurEnv()
+
+	credentials, err := godaddy.LoadCredentials(ctx, env)
+	if err != nil {
+		logging.Criticalf(ctx, "Error loading GoDaddy credentials: %s", err.Error())
+		os.Exit(-1)
+	}
+
+	limiter := httpService.NewLimiter(httpService.DefaultRateLimit)
+	httpClient := httpService.NewService(
+		&http.Client{Timeout: httpClientTimeout},
+		httpService.WithRateLimiter(limiter),
+		httpService.WithInterceptors(
+			httpService.RequestID(),
+			httpService.Timing(),
+			httpService.Logging(),
+		),
+	)
+
+	var tenants godaddy.TenantRegistry
+	if tenantsFile := os.Getenv("GODADDY_TENANTS_FILE"); tenantsFile != "" {
+		tenants, err = godaddy.LoadTenantRegistry(tenantsFile)
+		if err != nil {
+			logging.Criticalf(ctx, "Error loading tenants: %s", err.Error())
+			os.Exit(-1)
+		}
+	}
+
+	// without principals every request is anonymous, and may act on behalf of any tenant
+	var principals *principalSet
+	if principalsFile := os.Getenv("GODADDY_PRINCIPALS_FILE"); principalsFile != "" {
+		principals, err = loadPrincipalSet(principalsFile)
+		if err != nil {
+			logging.Criticalf(ctx, "Error loading principals: %s", err.Error())
+			os.Exit(-1)
+		}
+	} else if env == config.Prod || env == config.Demo {
+		logging.Criticalf(ctx, "GODADDY_PRINCIPALS_FILE must be set in %s", env.Name())
+		os.Exit(-1)
+	}
+
+	// the client IP recorded with consents is only read from X-Forwarded-For behind these proxies
+	trustedProxies, err := parseTrustedProxies(os.Getenv("GODADDY_TRUSTED_PROXIES"))
+	if err != nil {
+		logging.Criticalf(ctx, "Error parsing GODADDY_TRUSTED_PROXIES: %s", err.Error())
+		os.Exit(-1)
+	}
+
+	endpoint, err := godaddyEndpoint(env, os.Getenv("GODADDY_BASE_URL"))
+	if err != nil {
+		logging.Criticalf(ctx, "Error choosing the GoDaddy API: %s", err.Error())
+		os.Exit(-1)
+	}
+	if endpoint != godaddy.EndpointForEnv(env) {
+		logging.Infof(ctx, "Using GoDaddy stand-in at %s", endpoint.BaseURL)
+	}
+	if err := godaddy.CheckCredentialsEndpoint(credentials, endpoint); err != nil {
+		logging.Criticalf(ctx, "Error checking GoDaddy credentials: %s", err.Error())
+		os.Exit(-1)
+	}
+	godaddyOpts := []godaddy.Option{godaddy.WithCredentials(credentials), godaddy.WithEndpoint(endpoint)}
+	if nameservers := os.Getenv("GODADDY_DEFAULT_NAMESERVERS"); nameservers != "" {
+		godaddyOpts = append(godaddyOpts, godaddy.WithDefaultNameservers(strings.Split(nameservers, ",")...))
+	}
+	auditStore, err := openAuditStore(ctx, env)
+	if err != nil {
+		logging.Criticalf(ctx, "Error opening consent audit trail: %s", err.Error())
+		os.Exit(-1)
+	}
 
-	httpClient := httpService.NewService(&http.Client{})
+	// the catalog is loaded in the background, the endpoints relying on it work without it until it is
+	var catalogOpts []godaddy.CatalogOption
+	if catalogTLDs := os.Getenv("GODADDY_CATALOG_TLDS"); catalogTLDs != "" {
+		catalogOpts = append(catalogOpts, godaddy.WithCatalogTLDs(strings.Split(catalogTLDs, ",")...))
+	}
+	catalog := godaddy.NewTLDCatalog(godaddy.NewService(httpClient, godaddyOpts...), catalogOpts...)
 
-	godaddyService := godaddy.NewService(httpClient)
+	// purchases are checked against the schemas of the catalog, only the ones it hasn't loaded are fetched
+	godaddyOpts = append(godaddyOpts, godaddy.WithSchemaSource(catalog))
+	godaddyService := godaddy.NewAuditedService(godaddy.NewService(httpClient, godaddyOpts...), auditStore)
+	go catalog.Watch(ctx, catalogRefreshInterval)
 
 	//Start Healthz and Debug HTTP API Server
 	healthz := func(w http.ResponseWriter, _ *http.Request) {
@@ -37,141 +131,124 @@ func main() {
 	mux.HandleFunc("/healthz", healthz)
 
 	mux.HandleFunc("/domain-availability", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
 		domain := "slacknotification.biz"
-		available, price, err := godaddyService.GetDomainAvailabilityAndPrice(ctx, domain)
-
-		type response struct {
-			Available bool  `json:"available"`
-			Price     int64 `json:"price"`
-		}
-		resp := response{Available: available, Price: price}
-
-		jsonResp, err := json.Marshal(resp)
+		availability, err := godaddyService.GetDomainAvailability(ctx, domain)
 		if err != nil {
-			logging.Errorf(ctx, "Failed to marshal response %#v to json", resp)
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			logging.Errorf(ctx, "Error getting domain availability and price for %s: %s", domain, err.Error())
+			writeError(ctx, w, err)
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		w.Write(jsonResp)
+		writeJSON(ctx, w, http.StatusOK, availability)
 
 	})
 
-	mux.HandleFunc("/purchase-domain", func(w http.ResponseWriter, r *http.Request) {
-		domain := "Crystalisland.io"
-		contact := godaddy.Contact{
-			AddressMailing: godaddy.AddressMailing{
-				Address1:   "123",
-				City:       "Saskatoon",
-				Country:    "CA",
-				PostalCode: "S7S1N5",
-				State:      "SK",
-			},
-			Email:     "glu+test@vendasta.com",
-			NameFirst: "Gary",
-			NameLast:  "Lu",
-			Phone:     "+123.4511111111",
+	mux.HandleFunc("/purchase-agreements", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		type request struct {
+			TLD         string `json:"tld"`
+			Privacy     bool   `json:"privacy"`
+			ForTransfer bool   `json:"forTransfer"`
 		}
-		consent := godaddy.Consent{
-			AgreedAt:      "",
-			AgreedBy:      "",
-			AgreementKeys: []string{"DNRA"},
+		req := request{}
+		if !readJSON(ctx, w, r, &req, "{tld: string, privacy: bool, forTransfer: bool}") {
+			return
 		}
-		err := godaddyService.PurchaseDomain(ctx, domain, contact, consent)
 
+		agreements, err := godaddyService.GetPurchaseAgreement(ctx, req.TLD, godaddy.AgreementOptions{Privacy: req.Privacy, ForTransfer: req.ForTransfer})
 		if err != nil {
-			logging.Errorf(ctx, "Error: %s", err.Error())
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			logging.Errorf(ctx, "Error getting purchase agreements of TLD %s: %s", req.TLD, err.Error())
+			writeError(ctx, w, err)
 			return
 		}
-		w.WriteHeader(http.StatusOK)
+
+		type response struct {
+			Agreements []godaddy.Agreement `json:"agreements"`
+		}
+		writeJSON(ctx, w, http.StatusOK, response{Agreements: agreements})
 	})
 
 	mux.HandleFunc("/domain-suggest", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
 		type request struct {
-			Domain string `json:"domain"`
+			Domain    string   `json:"domain"`
+			Country   string   `json:"country"`
+			City      string   `json:"city"`
+			Sources   []string `json:"sources"`
+			TLDs      []string `json:"tlds"`
+			LengthMax int      `json:"lengthMax"`
+			LengthMin int      `json:"lengthMin"`
+			WaitMs    int      `json:"waitMs"`
+			Limit     int      `json:"limit"`
 		}
 		req := request{}
-
-		err := json.NewDecoder(r.Body).Decode(&req)
-		if err != nil {
-			logging.Errorf(ctx, "Failed to parse request: %#v", r)
-			http.Error(w, "Error processing request, expected {domain: string}", http.StatusBadRequest)
+		if !readJSON(ctx, w, r, &req, "{domain: string, country: string, city: string, sources: [string], tlds: [string], lengthMax: int, lengthMin: int, waitMs: int, limit: int}") {
 			return
 		}
 
 		domain := req.Domain
 
-		sDomains, err := godaddyService.GetDomainSuggestions(ctx, domain)
+		sDomains, err := godaddyService.GetDomainSuggestions(ctx, godaddy.SuggestOptions{
+			Query:     domain,
+			Country:   req.Country,
+			City:      req.City,
+			Sources:   req.Sources,
+			TLDs:      req.TLDs,
+			LengthMax: req.LengthMax,
+			LengthMin: req.LengthMin,
+			WaitMs:    req.WaitMs,
+			Limit:     req.Limit,
+		})
 
 		if err != nil {
 			logging.Errorf(ctx, "Error getting domain suggestion for %s: %s", domain, err.Error())
-			http.Error(w, "Error getting domain suggestion", http.StatusInternalServerError)
+			writeError(ctx, w, err)
 			return
 		}
 
 		type suggestion struct {
-			Domain string `json:"domain"`
-			Price  int64  `json:"price"` // price in cent
+			Domain string        `json:"domain"`
+			Price  godaddy.Money `json:"price"`
 		}
 
 		type response struct {
 			Suggestion []suggestion
 		}
 
-		type mutexSuggestions struct {
-			mu sync.Mutex
-			s  []suggestion
-		}
-
-		domainAndPrice := &mutexSuggestions{}
-		g, ctx := errgroup.WithContext(ctx)
-
-		for i, d := range sDomains {
-			g.Go(func(index int, domain string) func() error {
-				return func() error {
-					logging.Infof(ctx, "Getting price for %s", domain)
-					avail, price, err := godaddyService.GetDomainAvailabilityAndPrice(ctx, domain)
-					if err != nil {
-						logging.Errorf(ctx, "Error getting domain availability and price for %s: %s", domain, err.Error())
-						return err
-					}
-					if avail {
-						domainAndPrice.mu.Lock()
-						domainAndPrice.s = append(domainAndPrice.s, suggestion{
-							Domain: domain,
-							Price:  price,
-						})
-						domainAndPrice.mu.Unlock()
-					}
-					return nil
-				}
-			}(i, d))
+		if len(sDomains) == 0 {
+			writeJSON(ctx, w, http.StatusOK, response{Suggestion: []suggestion{}})
+			return
 		}
 
-		err = g.Wait()
+		availability, err := godaddyService.GetDomainsAvailability(ctx, sDomains, godaddy.CheckTypeFast)
 		if err != nil {
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			logging.Errorf(ctx, "Error getting domains availability for suggestions of %s: %s", domain, err.Error())
+			writeError(ctx, w, err)
+			return
+		}
+		for _, e := range availability.Errors {
+			logging.Warningf(ctx, "Availability of suggestion %s is unknown: %s %s", e.Domain, e.Code, e.Message)
 		}
 
-		jsonResp, err := json.Marshal(response{Suggestion: domainAndPrice.s})
-		if err != nil {
-			logging.Errorf(ctx, "Failed to marshal response %v to json", domainAndPrice)
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-			return
+		suggestions := make([]suggestion, 0, len(availability.Domains))
+		for _, a := range availability.Domains {
+			if a.Available {
+				suggestions = append(suggestions, suggestion{
+					Domain: a.Domain,
+					Price:  a.Price,
+				})
+			}
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		w.Write(jsonResp)
+		writeJSON(ctx, w, http.StatusOK, response{Suggestion: suggestions})
 
 		return
 
 	})
 
 	mux.HandleFunc("/list-dns", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
 		type request struct {
 			Domain string `json:"domain"`
 		}
@@ -179,8 +256,8 @@ func main() {
 
 		err := json.NewDecoder(r.Body).Decode(&req)
 		if err != nil {
-			logging.Errorf(ctx, "Failed to parse request: %#v", r)
-			http.Error(w, "Error processing request, expected {domain: string}", http.StatusBadRequest)
+			logging.Errorf(ctx, "Failed to parse request to %s: %v", r.URL.Path, err)
+			writeError(ctx, w, util.Error(util.InvalidArgument, "Error processing request, expected {domain: string}"))
 			return
 		}
 
@@ -203,7 +280,7 @@ func main() {
 						return err
 					}
 
-					logging.Infof(ctx, "DNS records of type %s: %s", DNSType, records)
+					logging.Infof(ctx, "DNS records of type %s: %v", DNSType, records)
 					dnsRecords.mu.Lock()
 					dnsRecords.r = append(dnsRecords.r, records...)
 					dnsRecords.mu.Unlock()
@@ -213,7 +290,7 @@ func main() {
 		}
 		err = g.Wait()
 		if err != nil {
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			writeError(ctx, w, err)
 			return
 		}
 
@@ -221,33 +298,25 @@ func main() {
 			DNSRecords []godaddy.DNSRecord `json:"records"`
 		}
 
-		jsonResp, err := json.Marshal(response{DNSRecords: dnsRecords.r})
-		if err != nil {
-			logging.Errorf(ctx, "Failed to marshal response %v to json", dnsRecords)
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-			return
-		}
-
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		w.Write(jsonResp)
+		writeJSON(ctx, w, http.StatusOK, response{DNSRecords: dnsRecords.r})
 		return
 	})
 
 	mux.HandleFunc("/put-dns", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
 		type request struct {
 			Domain string `json:"domain"`
-			Type string `json:"type"`
-			Name string `json:"name"`
-			Data string `json:"data"`
-			TTL int64 `json:"ttl"`
+			Type   string `json:"type"`
+			Name   string `json:"name"`
+			Data   string `json:"data"`
+			TTL    int64  `json:"ttl"`
 		}
 		req := request{}
 
 		err := json.NewDecoder(r.Body).Decode(&req)
 		if err != nil {
-			logging.Errorf(ctx, "Failed to parse request: %#v", r)
-			http.Error(w, "Error processing request, expected {domain: string}", http.StatusBadRequest)
+			logging.Errorf(ctx, "Failed to parse request to %s: %v", r.URL.Path, err)
+			writeError(ctx, w, util.Error(util.InvalidArgument, "Error processing request, expected {domain: string}"))
 			return
 		}
 
@@ -256,7 +325,7 @@ func main() {
 			Type: req.Type,
 			Name: req.Name,
 			Data: req.Data,
-			TTL: req.TTL,
+			TTL:  req.TTL,
 		}
 
 		// Add validations
@@ -264,7 +333,7 @@ func main() {
 		err = godaddyService.PutDNSRecord(ctx, domain, dnsRecord)
 		if err != nil {
 			logging.Errorf(ctx, "Error putting DNS records for domain %s: %s", domain, err.Error())
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			writeError(ctx, w, err)
 			return
 		}
 
@@ -272,8 +341,17 @@ func main() {
 		return
 	})
 
+	registerDebugHandlers(mux, limiter)
+	registerPurchaseHandlers(mux, godaddyService, catalog)
+	registerDomainHandlers(mux, godaddyService)
+	registerTransferHandlers(mux, godaddyService)
+	registerNameserverHandlers(mux, godaddyService)
+	registerAuditHandlers(mux, auditStore)
+	registerTLDHandlers(mux, catalog)
+	registerFormHandlers(mux, catalog)
+
 	logging.Infof(ctx, "Starting HTTP server...")
-	serverconfig.StartAndListenServer(ctx, grpc.NewServer(), mux, httpPort)
+	serverconfig.StartAndListenServer(ctx, grpc.NewServer(), withRequestID(withClientIP(trustedProxies, withPrincipal(principals, withActor(withTenant(tenants, mux))))), httpPort)
 
 	//for i := 0; i<100; i++ {
 	//	//domain := randomdata.FirstName(randomdata.RandomGender) + randomdata.LastName() + ".ca"
@@ -302,3 +380,14 @@ func main() {
 	//}
 	//return
 }
+
+// godaddyEndpoint returns the GoDaddy API of the environment, or the stand-in at baseURL, which production refuses
+func godaddyEndpoint(env config.Env, baseURL string) (godaddy.Endpoint, error) {
+	if baseURL == "" {
+		return godaddy.EndpointForEnv(env), nil
+	}
+	if env == config.Prod {
+		return godaddy.Endpoint{}, util.Error(util.FailedPrecondition, "GODADDY_BASE_URL must not be set in %s", env.Name())
+	}
+	return godaddy.Endpoint{BaseURL: baseURL, APIVersion: "v1"}, nil
+}
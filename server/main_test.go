@@ -0,0 +1,39 @@
+package main
+
+import (
+	"github.com/glucn/godaddy/internal/godaddy"
+	"github.com/vendasta/gosdks/config"
+	"github.com/vendasta/gosdks/util"
+	"testing"
+)
+
+func TestGodaddyEndpoint(t *testing.T) {
+	standIn := godaddy.Endpoint{BaseURL: "http://127.0.0.1:8080", APIVersion: "v1"}
+	cases := []struct {
+		name    string
+		env     config.Env
+		baseURL string
+		want    godaddy.Endpoint
+		refused bool
+	}{
+		{name: "production", env: config.Prod, want: godaddy.ProductionEndpoint},
+		{name: "demo", env: config.Demo, want: godaddy.OTEEndpoint},
+		{name: "stand-in in test", env: config.Test, baseURL: standIn.BaseURL, want: standIn},
+		{name: "stand-in in local", env: config.Local, baseURL: standIn.BaseURL, want: standIn},
+		{name: "stand-in in production", env: config.Prod, baseURL: standIn.BaseURL, refused: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := godaddyEndpoint(c.env, c.baseURL)
+			if c.refused {
+				if util.FromError(err).ErrorType() != util.FailedPrecondition {
+					t.Errorf("got %v, %v, want FailedPrecondition", got, err)
+				}
+				return
+			}
+			if err != nil || got != c.want {
+				t.Errorf("got %v, %v, want %v", got, err, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"github.com/glucn/godaddy/internal/audit"
+	"github.com/glucn/godaddy/internal/godaddy"
+	httpService "github.com/glucn/godaddy/internal/http"
+	"github.com/vendasta/gosdks/logging"
+	"github.com/vendasta/gosdks/util"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// orderTimeout bounds an order placed on a detached context, it leaves room for the GoDaddy purchase timeout
+const orderTimeout = 90 * time.Second
+
+// detach returns a context carrying the values of the request context, such as the tenant and the request ID, that is
+// not cancelled when the client disconnects. Orders spend money and must not be abandoned mid-flight.
+func detach(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), orderTimeout)
+}
+
+// withRequestID carries the request ID of the inbound request, or a new one, to the outbound GoDaddy calls
+func withRequestID(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(httpService.HeaderRequestID)
+		if requestID == "" {
+			requestID = httpService.NewRequestID()
+		}
+		w.Header().Set(httpService.HeaderRequestID, requestID)
+		h.ServeHTTP(w, r.WithContext(httpService.WithRequestID(r.Context(), requestID)))
+	})
+}
+
+// headerTenantID names the tenant an inbound request is made on behalf of
+const headerTenantID = "X-Tenant-Id"
+
+// withTenant resolves the tenant of the inbound request, which the authenticated principal must be allowed to act on
+// behalf of. Requests without one use the default GoDaddy identity, if the principal may.
+func withTenant(registry godaddy.TenantRegistry, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		caller, ok := principalFromContext(ctx)
+		if !ok {
+			if unauthenticatedPaths[r.URL.Path] {
+				h.ServeHTTP(w, r)
+				return
+			}
+			writeError(ctx, w, util.Error(util.Unauthenticated, "Request is not authenticated"))
+			return
+		}
+
+		tenantID := r.Header.Get(headerTenantID)
+		if tenantID == "" {
+			if !caller.DefaultIdentity {
+				writeError(ctx, w, util.Error(util.InvalidArgument, "%s is required", headerTenantID))
+				return
+			}
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		if !caller.mayActFor(tenantID) {
+			logging.Warningf(ctx, "Principal %s may not act on behalf of tenant %s", caller.ID, tenantID)
+			writeError(ctx, w, util.Error(util.PermissionDenied, "Principal %s may not act on behalf of tenant %s", caller.ID, tenantID))
+			return
+		}
+		if registry == nil {
+			writeError(ctx, w, util.Error(util.InvalidArgument, "Tenants are not configured"))
+			return
+		}
+		tenant, err := registry.Tenant(ctx, tenantID)
+		if err != nil {
+			logging.Warningf(ctx, "Error resolving tenant %s: %s", tenantID, err.Error())
+			writeError(ctx, w, util.Error(util.PermissionDenied, "Unknown tenant %s", tenantID))
+			return
+		}
+		h.ServeHTTP(w, r.WithContext(godaddy.WithTenant(ctx, tenant)))
+	})
+}
+
+// headerUserID names the end-user a principal makes an inbound request for
+const headerUserID = "X-User-Id"
+
+// withActor carries who makes the inbound request to the consent audit trail: the authenticated principal, and the
+// user it acts for when it may name one
+func withActor(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		caller, ok := principalFromContext(ctx)
+		if !ok {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		actor := caller.ID
+		if userID := r.Header.Get(headerUserID); userID != "" {
+			if !caller.ActsForUsers {
+				writeError(ctx, w, util.Error(util.PermissionDenied, "Principal %s may not act on behalf of users", caller.ID))
+				return
+			}
+			actor = caller.ID + "/" + userID
+		}
+		h.ServeHTTP(w, r.WithContext(audit.WithActor(ctx, actor)))
+	})
+}
+
+// parseTrustedProxies parses a comma separated list of the IP addresses and CIDR ranges of the proxies in front of
+// the server, ex: the load balancer
+func parseTrustedProxies(s string) ([]*net.IPNet, error) {
+	proxies := []*net.IPNet{}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, util.Error(util.InvalidArgument, "Invalid trusted proxy %q", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, util.Error(util.InvalidArgument, "Invalid trusted proxy %q", entry)
+		}
+		proxies = append(proxies, network)
+	}
+	return proxies, nil
+}
+
+func isTrustedProxy(proxies []*net.IPNet, ip net.IP) bool {
+	for _, network := range proxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+type clientIPKey struct{}
+
+// withClientIP resolves the IP address of the end-user making the inbound request. X-Forwarded-For is only read when
+// the connection comes from a trusted proxy, and only the hops appended by trusted proxies are believed: the client
+// is the rightmost address that is not a trusted proxy, anything left of it may be forged.
+func withClientIP(proxies []*net.IPNet, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client := remoteIP(r)
+		if ip := net.ParseIP(client); ip != nil && isTrustedProxy(proxies, ip) {
+			hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+			for i := len(hops) - 1; i >= 0; i-- {
+				hop := net.ParseIP(strings.TrimSpace(hops[i]))
+				if hop == nil {
+					break
+				}
+				client = hop.String()
+				if !isTrustedProxy(proxies, hop) {
+					break
+				}
+			}
+		}
+		h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientIPKey{}, client)))
+	})
+}
+
+// remoteIP returns the IP address of the connection of the request
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// clientIP returns the IP address of the end-user making the request, as resolved by withClientIP
+func clientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPKey{}).(string); ok {
+		return ip
+	}
+	return remoteIP(r)
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/glucn/godaddy/internal/audit"
+	"github.com/glucn/godaddy/internal/godaddy"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func tokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func testPrincipals(t *testing.T) *principalSet {
+	t.Helper()
+	principals, err := newPrincipalSet([]principal{
+		{ID: "acme-backend", TokenSHA256: tokenHash("acme-token"), Tenants: []string{"acme"}},
+		{ID: "ops", TokenSHA256: tokenHash("ops-token"), DefaultIdentity: true},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	return principals
+}
+
+func testTenants(t *testing.T) godaddy.TenantRegistry {
+	t.Helper()
+	tenants, err := godaddy.NewTenantRegistry([]godaddy.Tenant{
+		{ID: "acme", ShopperID: "1001"},
+		{ID: "globex", ShopperID: "2002"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	return tenants
+}
+
+// tenantOf answers with the shopper ID of the tenant the request is made on behalf of, "default" if there is none
+var tenantOf = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	shopperID := "default"
+	if tenant, ok := godaddy.TenantFromContext(r.Context()); ok {
+		shopperID = tenant.ShopperID
+	}
+	w.Write([]byte(shopperID))
+})
+
+func TestWithTenant(t *testing.T) {
+	cases := []struct {
+		name       string
+		token      string
+		tenantID   string
+		statusCode int
+		shopperID  string
+	}{
+		{name: "tenant of the principal", token: "acme-token", tenantID: "acme", statusCode: http.StatusOK, shopperID: "1001"},
+		{name: "tenant of another principal", token: "acme-token", tenantID: "globex", statusCode: http.StatusForbidden},
+		{name: "unknown tenant", token: "ops-token", tenantID: "initech", statusCode: http.StatusForbidden},
+		{name: "fallback to the default identity", token: "ops-token", statusCode: http.StatusOK, shopperID: "default"},
+		{name: "no default identity for the principal", token: "acme-token", statusCode: http.StatusBadRequest},
+		{name: "unknown token", token: "guess", tenantID: "acme", statusCode: http.StatusUnauthorized},
+		{name: "no token", tenantID: "acme", statusCode: http.StatusUnauthorized},
+	}
+	h := withPrincipal(testPrincipals(t), withTenant(testTenants(t), tenantOf))
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/purchase-domain", nil)
+			if c.token != "" {
+				r.Header.Set("Authorization", "Bearer "+c.token)
+			}
+			if c.tenantID != "" {
+				r.Header.Set(headerTenantID, c.tenantID)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != c.statusCode {
+				t.Fatalf("got status %d, want %d: %s", w.Code, c.statusCode, w.Body.String())
+			}
+			if c.shopperID != "" && w.Body.String() != c.shopperID {
+				t.Errorf("got shopper %q, want %q", w.Body.String(), c.shopperID)
+			}
+		})
+	}
+}
+
+func TestWithTenantAnonymous(t *testing.T) {
+	h := withPrincipal(nil, withTenant(testTenants(t), tenantOf))
+
+	for tenantID, shopperID := range map[string]string{"": "default", "globex": "2002"} {
+		r := httptest.NewRequest(http.MethodPost, "/purchase-domain", nil)
+		if tenantID != "" {
+			r.Header.Set(headerTenantID, tenantID)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if w.Code != http.StatusOK || w.Body.String() != shopperID {
+			t.Errorf("tenant %q: got %d %q, want %q", tenantID, w.Code, w.Body.String(), shopperID)
+		}
+	}
+}
+
+func TestHealthzIsNotAuthenticated(t *testing.T) {
+	h := withPrincipal(testPrincipals(t), withTenant(testTenants(t), tenantOf))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("got status %d, want the health check to be served", w.Code)
+	}
+}
+
+func TestNewPrincipalSet(t *testing.T) {
+	for name, principals := range map[string][]principal{
+		"missing id":     {{TokenSHA256: tokenHash("token")}},
+		"duplicated id":  {{ID: "a", TokenSHA256: tokenHash("a")}, {ID: "a", TokenSHA256: tokenHash("b")}},
+		"clear token":    {{ID: "a", TokenSHA256: "acme-token"}},
+		"truncated hash": {{ID: "a", TokenSHA256: tokenHash("token")[:32]}},
+	} {
+		if _, err := newPrincipalSet(principals); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	proxies, err := parseTrustedProxies("130.211.0.0/22, 35.191.0.0/16,10.0.0.7")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	cases := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		want       string
+	}{
+		{name: "direct connection", remoteAddr: "203.0.113.7:52100", want: "203.0.113.7"},
+		{name: "forged header on a direct connection", remoteAddr: "203.0.113.7:52100", forwarded: []string{"198.51.100.1"}, want: "203.0.113.7"},
+		{name: "behind the load balancer", remoteAddr: "35.191.10.1:40000", forwarded: []string{"203.0.113.7, 130.211.0.5"}, want: "203.0.113.7"},
+		{name: "forged hop behind the load balancer", remoteAddr: "35.191.10.1:40000", forwarded: []string{"198.51.100.1, 203.0.113.7, 130.211.0.5"}, want: "203.0.113.7"},
+		{name: "hops in several headers", remoteAddr: "10.0.0.7:40000", forwarded: []string{"198.51.100.1", "203.0.113.7"}, want: "203.0.113.7"},
+		{name: "invalid hop", remoteAddr: "10.0.0.7:40000", forwarded: []string{"203.0.113.7, garbage"}, want: "10.0.0.7"},
+		{name: "no header behind a proxy", remoteAddr: "10.0.0.7:40000", want: "10.0.0.7"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/purchase-domain", nil)
+			r.RemoteAddr = c.remoteAddr
+			for _, f := range c.forwarded {
+				r.Header.Add("X-Forwarded-For", f)
+			}
+
+			var got string
+			withClientIP(proxies, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = clientIP(r)
+			})).ServeHTTP(httptest.NewRecorder(), r)
+			if got != c.want {
+				t.Errorf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	if proxies, err := parseTrustedProxies(""); err != nil || len(proxies) != 0 {
+		t.Errorf("got %v, %v, want no proxy", proxies, err)
+	}
+	for _, s := range []string{"10.0.0.0/33", "load-balancer"} {
+		if _, err := parseTrustedProxies(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
+
+func TestWithActor(t *testing.T) {
+	principals, err := newPrincipalSet([]principal{
+		{ID: "acme-backend", TokenSHA256: tokenHash("acme-token"), DefaultIdentity: true, ActsForUsers: true},
+		{ID: "ops", TokenSHA256: tokenHash("ops-token"), DefaultIdentity: true},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	cases := []struct {
+		name       string
+		token      string
+		userID     string
+		statusCode int
+		actor      string
+	}{
+		{name: "principal", token: "ops-token", statusCode: http.StatusOK, actor: "ops"},
+		{name: "user of the principal", token: "acme-token", userID: "jane", statusCode: http.StatusOK, actor: "acme-backend/jane"},
+		{name: "principal not acting for users", token: "ops-token", userID: "jane", statusCode: http.StatusForbidden},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/purchase-domain", nil)
+			r.Header.Set("Authorization", "Bearer "+c.token)
+			if c.userID != "" {
+				r.Header.Set(headerUserID, c.userID)
+			}
+
+			var actor string
+			w := httptest.NewRecorder()
+			withPrincipal(principals, withActor(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				actor = audit.ActorFromContext(r.Context())
+			}))).ServeHTTP(w, r)
+			if w.Code != c.statusCode {
+				t.Fatalf("got status %d, want %d", w.Code, c.statusCode)
+			}
+			if actor != c.actor {
+				t.Errorf("got actor %q, want %q", actor, c.actor)
+			}
+		})
+	}
+}
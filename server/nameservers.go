@@ -0,0 +1,83 @@
+package main
+
+import (
+	"github.com/glucn/godaddy/internal/godaddy"
+	"github.com/vendasta/gosdks/logging"
+	"github.com/vendasta/gosdks/util"
+	"net/http"
+)
+
+// nameserversResponse is the delegation of a domain
+type nameserversResponse struct {
+	Domain      string   `json:"domain"`
+	NameServers []string `json:"nameServers"`
+	// UsesGoDaddyNameservers tells whether NameServers are GoDaddy's, so that the DNS records managed through
+	// /list-dns and /put-dns are meant to be served. It says nothing of what DNS resolves, which lags behind the
+	// registry by up to 48 hours.
+	UsesGoDaddyNameservers bool `json:"usesGoDaddyNameservers"`
+}
+
+// registerNameserverHandlers registers the endpoints delegating domains to nameservers
+func registerNameserverHandlers(mux *http.ServeMux, godaddyService godaddy.Interface) {
+	mux.HandleFunc("/get-nameservers", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		type request struct {
+			Domain string `json:"domain"`
+		}
+		req := request{}
+		if !readJSON(ctx, w, r, &req, "{domain: string}") {
+			return
+		}
+
+		nameservers, err := godaddyService.GetNameservers(ctx, req.Domain)
+		if err != nil {
+			logging.Errorf(ctx, "Error getting nameservers of domain %s: %s", req.Domain, err.Error())
+			writeError(ctx, w, err)
+			return
+		}
+
+		writeJSON(ctx, w, http.StatusOK, nameserversResponse{
+			Domain:                 req.Domain,
+			NameServers:            nameservers,
+			UsesGoDaddyNameservers: godaddy.DelegatedToGoDaddy(nameservers),
+		})
+	})
+
+	// set-nameservers answers the nameservers submitted to GoDaddy rather than reading them back, GoDaddy applying the
+	// change asynchronously
+	mux.HandleFunc("/set-nameservers", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		type request struct {
+			Domain      string   `json:"domain"`
+			NameServers []string `json:"nameServers"`
+			UseDefault  bool     `json:"useDefault"`
+		}
+		req := request{}
+		if !readJSON(ctx, w, r, &req, "{domain: string, nameServers: [string], useDefault: bool}") {
+			return
+		}
+		if req.UseDefault && len(req.NameServers) > 0 {
+			writeError(ctx, w, util.Error(util.InvalidArgument, "nameServers must be empty when useDefault is set"))
+			return
+		}
+
+		var nameservers []string
+		var err error
+		if req.UseDefault {
+			nameservers, err = godaddyService.ResetNameservers(ctx, req.Domain)
+		} else {
+			nameservers, err = godaddyService.SetNameservers(ctx, req.Domain, req.NameServers)
+		}
+		if err != nil {
+			logging.Errorf(ctx, "Error setting nameservers of domain %s: %s", req.Domain, err.Error())
+			writeError(ctx, w, err)
+			return
+		}
+
+		writeJSON(ctx, w, http.StatusOK, nameserversResponse{
+			Domain:                 req.Domain,
+			NameServers:            nameservers,
+			UsesGoDaddyNameservers: godaddy.DelegatedToGoDaddy(nameservers),
+		})
+	})
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/glucn/godaddy/internal/godaddy"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeNameserverService applies nameserver changes lazily, GetNameservers answering the previous ones like GoDaddy
+// does until the change is applied
+type fakeNameserverService struct {
+	godaddy.Interface
+}
+
+func (fakeNameserverService) GetNameservers(ctx context.Context, domain string) ([]string, error) {
+	return []string{"ns1.example.net", "ns2.example.net"}, nil
+}
+
+func (fakeNameserverService) SetNameservers(ctx context.Context, domain string, nameservers []string) ([]string, error) {
+	return godaddy.NormalizeNameservers(nameservers)
+}
+
+func (fakeNameserverService) ResetNameservers(ctx context.Context, domain string) ([]string, error) {
+	return godaddy.DefaultNameservers, nil
+}
+
+func TestSetNameserversAnswersSubmittedNameservers(t *testing.T) {
+	cases := []struct {
+		name        string
+		body        string
+		want        []string
+		usesGoDaddy bool
+	}{
+		{name: "custom", body: `{"domain": "example.com", "nameServers": ["NS1.Example.org.", "ns2.example.org"]}`, want: []string{"ns1.example.org", "ns2.example.org"}},
+		{name: "default", body: `{"domain": "example.com", "useDefault": true}`, want: godaddy.DefaultNameservers, usesGoDaddy: true},
+	}
+	mux := http.NewServeMux()
+	registerNameserverHandlers(mux, fakeNameserverService{})
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/set-nameservers", strings.NewReader(c.body)))
+			if w.Code != http.StatusOK {
+				t.Fatalf("got status %d: %s", w.Code, w.Body.String())
+			}
+			got := nameserversResponse{}
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !reflect.DeepEqual(got.NameServers, c.want) || got.UsesGoDaddyNameservers != c.usesGoDaddy {
+				t.Errorf("got %+v, want the submitted nameservers %v", got, c.want)
+			}
+		})
+	}
+}
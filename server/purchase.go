@@ -0,0 +1,78 @@
+package main
+
+import (
+	"github.com/glucn/godaddy/internal/godaddy"
+	"github.com/vendasta/gosdks/logging"
+	"github.com/vendasta/gosdks/util"
+	"net/http"
+)
+
+// registerPurchaseHandlers registers the endpoints registering new domains, the TLDs of which are checked against the
+// catalog once it is loaded
+func registerPurchaseHandlers(mux *http.ServeMux, godaddyService godaddy.Interface, catalog *godaddy.TLDCatalog) {
+	mux.HandleFunc("/purchase-domain", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		type request struct {
+			Domain        string                 `json:"domain"`
+			Contacts      godaddy.DomainContacts `json:"contacts"`
+			AgreementKeys []string               `json:"agreementKeys"`
+			Period        int                    `json:"period"`
+			Privacy       bool                   `json:"privacy"`
+			RenewAuto     bool                   `json:"renewAuto"`
+			NameServers   []string               `json:"nameServers"`
+			Extra         map[string]interface{} `json:"extra"`
+		}
+		req := request{}
+		if !readJSON(ctx, w, r, &req, "{domain: string, contacts: object, agreementKeys: [string], period: int, privacy: bool, renewAuto: bool, nameServers: [string], extra: object}") {
+			return
+		}
+		domain := req.Domain
+		if !catalog.LoadedAt().IsZero() {
+			if _, ok := catalog.ForDomain(domain); !ok {
+				writeError(ctx, w, util.Error(util.InvalidArgument, "Domains under the TLD of %s are not sold", domain))
+				return
+			}
+		}
+
+		consent, err := godaddyService.BuildConsent(ctx, domain, godaddy.AgreementOptions{Privacy: req.Privacy}, req.AgreementKeys, clientIP(r))
+		if err != nil {
+			logging.Errorf(ctx, "Error building consent to the purchase of domain %s: %s", domain, err.Error())
+			writeError(ctx, w, err)
+			return
+		}
+		opts := godaddy.PurchaseOptions{
+			Period:      req.Period,
+			Privacy:     req.Privacy,
+			RenewAuto:   req.RenewAuto,
+			NameServers: req.NameServers,
+			Extra:       req.Extra,
+		}
+
+		// the purchase is validated first so that a bad field is reported without an order being attempted
+		problems, err := godaddyService.ValidatePurchase(ctx, domain, req.Contacts, consent, opts)
+		if err != nil {
+			logging.Errorf(ctx, "Error validating purchase of domain %s: %s", domain, err.Error())
+			writeError(ctx, w, err)
+			return
+		}
+		if r.URL.Query().Get("dryRun") == "true" {
+			writeJSON(ctx, w, http.StatusOK, purchaseValidation{Valid: len(problems) == 0, Fields: problems})
+			return
+		}
+		if len(problems) > 0 {
+			writeFieldErrors(ctx, w, "Purchase of domain "+domain+" is invalid", problems)
+			return
+		}
+
+		orderCtx, cancel := detach(ctx)
+		defer cancel()
+		result, err := godaddyService.PurchaseDomain(orderCtx, domain, req.Contacts, consent, opts)
+		if err != nil {
+			logging.Errorf(ctx, "Error purchasing domain %s: %s", domain, err.Error())
+			writeError(ctx, w, err)
+			return
+		}
+		logging.Infof(ctx, "Purchased domain %s for %d years in order %d, total %s", result.Domain, result.Period, result.OrderID, result.Total)
+		writeJSON(ctx, w, http.StatusOK, result)
+	})
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/glucn/godaddy/internal/godaddy"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakePurchaseService reports the given problems on every purchase, and records the orders placed
+type fakePurchaseService struct {
+	godaddy.Interface
+	problems []godaddy.FieldError
+	orders   []string
+}
+
+func (f *fakePurchaseService) BuildConsent(ctx context.Context, domain string, opts godaddy.AgreementOptions, agreementKeys []string, agreedBy string) (godaddy.Consent, error) {
+	return godaddy.Consent{AgreedAt: "2026-10-17T12:00:00Z", AgreedBy: agreedBy, AgreementKeys: agreementKeys}, nil
+}
+
+func (f *fakePurchaseService) ValidatePurchase(ctx context.Context, domain string, contacts godaddy.DomainContacts, consent godaddy.Consent, opts godaddy.PurchaseOptions) ([]godaddy.FieldError, error) {
+	return f.problems, nil
+}
+
+func (f *fakePurchaseService) PurchaseDomain(ctx context.Context, domain string, contacts godaddy.DomainContacts, consent godaddy.Consent, opts godaddy.PurchaseOptions) (godaddy.PurchaseResult, error) {
+	f.orders = append(f.orders, domain)
+	return godaddy.PurchaseResult{Domain: domain, Period: 1, OrderResult: godaddy.OrderResult{OrderID: 42}}, nil
+}
+
+func TestPurchaseDomainDryRun(t *testing.T) {
+	problem := godaddy.FieldError{Code: "MISMATCH_FORMAT", Message: "Invalid phone", Path: "contactRegistrant.phone"}
+	cases := []struct {
+		name     string
+		query    string
+		problems []godaddy.FieldError
+		status   int
+		valid    bool
+		ordered  bool
+	}{
+		{name: "dry run of a valid purchase", query: "?dryRun=true", status: http.StatusOK, valid: true},
+		{name: "dry run of an invalid purchase", query: "?dryRun=true", problems: []godaddy.FieldError{problem}, status: http.StatusOK},
+		{name: "invalid purchase", problems: []godaddy.FieldError{problem}, status: http.StatusBadRequest},
+		{name: "purchase", status: http.StatusOK, ordered: true},
+		{name: "dry run turned off", query: "?dryRun=false", status: http.StatusOK, ordered: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			service := &fakePurchaseService{problems: c.problems}
+			mux := http.NewServeMux()
+			registerPurchaseHandlers(mux, service, godaddy.NewTLDCatalog(fakeTLDService{}, godaddy.WithCatalogPace(0)))
+
+			w := httptest.NewRecorder()
+			body := `{"domain": "example.com", "agreementKeys": ["DNRA"], "period": 1}`
+			mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/purchase-domain"+c.query, strings.NewReader(body)))
+			if w.Code != c.status {
+				t.Fatalf("got status %d, want %d: %s", w.Code, c.status, w.Body.String())
+			}
+			if ordered := len(service.orders) > 0; ordered != c.ordered {
+				t.Errorf("got ordered %t, want %t", ordered, c.ordered)
+			}
+			if c.query != "?dryRun=true" {
+				return
+			}
+
+			got := purchaseValidation{}
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if got.Valid != c.valid || len(got.Fields) != len(c.problems) {
+				t.Errorf("got %+v, want valid %t with fields %+v", got, c.valid, c.problems)
+			}
+			if len(got.Fields) > 0 && got.Fields[0] != problem {
+				t.Errorf("got field %+v, want %+v", got.Fields[0], problem)
+			}
+		})
+	}
+}
+
+func TestPurchaseDomainRefusesTLDsNotSold(t *testing.T) {
+	service := &fakePurchaseService{}
+	catalog := godaddy.NewTLDCatalog(fakeTLDService{}, godaddy.WithCatalogPace(0))
+	if err := catalog.Refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	mux := http.NewServeMux()
+	registerPurchaseHandlers(mux, service, catalog)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/purchase-domain?dryRun=true", strings.NewReader(`{"domain": "example.us"}`)))
+	if w.Code != http.StatusBadRequest || len(service.orders) != 0 {
+		t.Errorf("got status %d and orders %v, want 400 without an order", w.Code, service.orders)
+	}
+}
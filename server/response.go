@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/glucn/godaddy/internal/godaddy"
+	"github.com/vendasta/gosdks/logging"
+	"github.com/vendasta/gosdks/util"
+	"net/http"
+)
+
+// errorBody is the JSON body answered when a request fails
+type errorBody struct {
+	Error errorDetail `json:"error"`
+}
+
+type errorDetail struct {
+	Type         string               `json:"type"`
+	Message      string               `json:"message"`
+	UpstreamCode string               `json:"upstreamCode,omitempty"`
+	Fields       []godaddy.FieldError `json:"fields,omitempty"`
+}
+
+// writeJSON writes v as a JSON response with the given status code
+func writeJSON(ctx context.Context, w http.ResponseWriter, statusCode int, v interface{}) {
+	jsonResp, err := json.Marshal(v)
+	if err != nil {
+		logging.Errorf(ctx, "Failed to marshal response %#v to json", v)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(jsonResp)
+}
+
+// writeError translates err into a status code and a JSON error body. The message of every error type in
+// util.DefaultErrorMask is masked, GoDaddy errors included, only their code and field problems are passed through.
+func writeError(ctx context.Context, w http.ResponseWriter, err error) {
+	serviceErr := util.FromError(err)
+	detail := errorDetail{
+		Type:    serviceErr.ErrorType().String(),
+		Message: serviceErr.Error(),
+	}
+	if apiErr, ok := err.(*godaddy.APIError); ok {
+		detail.UpstreamCode = apiErr.Code
+		detail.Fields = apiErr.Fields
+	}
+	if validationErr, ok := err.(*godaddy.ValidationError); ok {
+		detail.Fields = validationErr.Fields
+	}
+	if mask, ok := util.DefaultErrorMask[serviceErr.ErrorType()]; ok {
+		detail.Message = mask
+	}
+
+	writeJSON(ctx, w, serviceErr.HTTPCode(), errorBody{Error: detail})
+}
+
+// purchaseValidation is the outcome of a purchase dry-run
+type purchaseValidation struct {
+	Valid  bool                 `json:"valid"`
+	Fields []godaddy.FieldError `json:"fields,omitempty"`
+}
+
+// writeFieldErrors answers 400 with the problems found on the fields of a request
+func writeFieldErrors(ctx context.Context, w http.ResponseWriter, message string, fields []godaddy.FieldError) {
+	writeJSON(ctx, w, http.StatusBadRequest, errorBody{Error: errorDetail{
+		Type:    util.InvalidArgument.String(),
+		Message: message,
+		Fields:  fields,
+	}})
+}
+
+// readJSON decodes the JSON body of a request into v, answering 400 with the expected format when it can't
+func readJSON(ctx context.Context, w http.ResponseWriter, r *http.Request, v interface{}, expected string) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		logging.Errorf(ctx, "Failed to parse request to %s: %v", r.URL.Path, err)
+		writeError(ctx, w, util.Error(util.InvalidArgument, "Error processing request, expected %s", expected))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/glucn/godaddy/internal/godaddy"
+	"github.com/vendasta/gosdks/util"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteError(t *testing.T) {
+	fields := []godaddy.FieldError{{Code: "MISSING_PROPERTY", Path: "contactAdmin.email"}}
+	cases := []struct {
+		name         string
+		err          error
+		statusCode   int
+		message      string
+		upstreamCode string
+		fields       int
+	}{
+		{
+			name:       "internal error is masked",
+			err:        util.Error(util.Internal, "open /etc/godaddy/secret: permission denied"),
+			statusCode: http.StatusInternalServerError,
+			message:    util.DefaultErrorMask[util.Internal],
+		},
+		{
+			name:       "invalid argument is passed through",
+			err:        util.Error(util.InvalidArgument, "Period must be between 1 and 10"),
+			statusCode: http.StatusBadRequest,
+			message:    "Period must be between 1 and 10",
+		},
+		{
+			name:         "upstream authentication failure is masked",
+			err:          &godaddy.APIError{StatusCode: 401, Code: "UNABLE_TO_AUTHENTICATE", Message: "Malformed credentials sso-key abc"},
+			statusCode:   http.StatusUnauthorized,
+			message:      util.DefaultErrorMask[util.Unauthenticated],
+			upstreamCode: "UNABLE_TO_AUTHENTICATE",
+		},
+		{
+			name:         "upstream permission failure is masked",
+			err:          &godaddy.APIError{StatusCode: 403, Code: "ACCESS_DENIED", Message: "Shopper 1234 may not act on reseller 42"},
+			statusCode:   http.StatusForbidden,
+			message:      util.DefaultErrorMask[util.PermissionDenied],
+			upstreamCode: "ACCESS_DENIED",
+		},
+		{
+			name:         "upstream failure is masked",
+			err:          &godaddy.APIError{StatusCode: 500, Code: "INTERNAL_SERVER_ERROR", Message: "NullPointerException at OrderService"},
+			statusCode:   http.StatusServiceUnavailable,
+			message:      util.DefaultErrorMask[util.Unavailable],
+			upstreamCode: "INTERNAL_SERVER_ERROR",
+		},
+		{
+			name:         "upstream field problems are passed through",
+			err:          &godaddy.APIError{StatusCode: 422, Code: "INVALID_BODY", Message: "Request body doesn't fulfill schema", Fields: fields},
+			statusCode:   http.StatusBadRequest,
+			message:      "Request body doesn't fulfill schema",
+			upstreamCode: "INVALID_BODY",
+			fields:       1,
+		},
+		{
+			name:       "validation problems are passed through",
+			err:        &godaddy.ValidationError{Message: "Purchase of example.com is invalid", Fields: fields},
+			statusCode: http.StatusBadRequest,
+			message:    "Purchase of example.com is invalid",
+			fields:     1,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			writeError(context.Background(), w, c.err)
+
+			if w.Code != c.statusCode {
+				t.Errorf("got status %d, want %d", w.Code, c.statusCode)
+			}
+			body := errorBody{}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("unexpected error %v decoding %s", err, w.Body.String())
+			}
+			if body.Error.Message != c.message {
+				t.Errorf("got message %q, want %q", body.Error.Message, c.message)
+			}
+			if body.Error.UpstreamCode != c.upstreamCode {
+				t.Errorf("got upstream code %q, want %q", body.Error.UpstreamCode, c.upstreamCode)
+			}
+			if len(body.Error.Fields) != c.fields {
+				t.Errorf("got %d fields, want %d", len(body.Error.Fields), c.fields)
+			}
+		})
+	}
+}
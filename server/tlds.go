@@ -0,0 +1,36 @@
+package main
+
+import (
+	"github.com/glucn/godaddy/internal/godaddy"
+	"github.com/vendasta/gosdks/util"
+	"net/http"
+	"time"
+)
+
+// registerTLDHandlers registers the endpoints browsing the TLD catalog
+func registerTLDHandlers(mux *http.ServeMux, catalog *godaddy.TLDCatalog) {
+	// tlds is a read-only listing, filtered by the query parameters type, query and agreementKey
+	mux.HandleFunc("/tlds", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		params := r.URL.Query()
+		tldType := godaddy.TLDType(params.Get("type"))
+		if tldType != "" && tldType != godaddy.TLDTypeGeneric && tldType != godaddy.TLDTypeCountryCode {
+			writeError(ctx, w, util.Error(util.InvalidArgument, "Type must be GENERIC or COUNTRY_CODE"))
+			return
+		}
+		loadedAt := catalog.LoadedAt()
+		if loadedAt.IsZero() {
+			writeError(ctx, w, util.Error(util.Unavailable, "The TLD catalog is loading"))
+			return
+		}
+
+		type response struct {
+			TLDs     []godaddy.CatalogTLD `json:"tlds"`
+			LoadedAt time.Time            `json:"loadedAt"`
+		}
+		writeJSON(ctx, w, http.StatusOK, response{
+			TLDs:     catalog.List(godaddy.TLDFilter{Type: tldType, Query: params.Get("query"), AgreementKey: params.Get("agreementKey")}),
+			LoadedAt: loadedAt,
+		})
+	})
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/glucn/godaddy/internal/godaddy"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// fakeTLDService lists a few TLDs, with the details GoDaddy would answer for each
+type fakeTLDService struct {
+	godaddy.Interface
+}
+
+func (fakeTLDService) ListTLDs(ctx context.Context) ([]godaddy.TLD, error) {
+	return []godaddy.TLD{{Name: "ca", Type: godaddy.TLDTypeCountryCode}, {Name: "com", Type: godaddy.TLDTypeGeneric}}, nil
+}
+
+func (fakeTLDService) GetPurchaseAgreement(ctx context.Context, tld string, opts godaddy.AgreementOptions) ([]godaddy.Agreement, error) {
+	return []godaddy.Agreement{{Key: "DNRA", Title: "Registration agreement"}}, nil
+}
+
+func (fakeTLDService) GetPurchaseSchema(ctx context.Context, tld string) (godaddy.PurchaseSchema, error) {
+	return godaddy.PurchaseSchema{Required: []string{"domain"}}, nil
+}
+
+func TestListTLDs(t *testing.T) {
+	mux := http.NewServeMux()
+	catalog := godaddy.NewTLDCatalog(fakeTLDService{}, godaddy.WithCatalogPace(0))
+	registerTLDHandlers(mux, catalog)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tlds", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("got status %d, want 503 until the catalog is loaded", w.Code)
+	}
+
+	if err := catalog.Refresh(context.Background()); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tlds?type=COUNTRY_CODE&agreementKey=DNRA", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", w.Code, w.Body.String())
+	}
+	body := struct {
+		TLDs []godaddy.CatalogTLD `json:"tlds"`
+	}{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(body.TLDs) != 1 || body.TLDs[0].Name != "ca" {
+		t.Errorf("got %+v, want ca", body.TLDs)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/tlds?type=SPONSORED", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("got status %d, want 400 for an unknown type", w.Code)
+	}
+}
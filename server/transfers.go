@@ -0,0 +1,73 @@
+package main
+
+import (
+	"github.com/glucn/godaddy/internal/godaddy"
+	"github.com/vendasta/gosdks/logging"
+	"net/http"
+)
+
+// registerTransferHandlers registers the endpoints transferring domains in from other registrars
+func registerTransferHandlers(mux *http.ServeMux, godaddyService godaddy.Interface) {
+	mux.HandleFunc("/transfer-domain", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		type request struct {
+			Domain        string                 `json:"domain"`
+			AuthCode      string                 `json:"authCode"`
+			Contacts      godaddy.DomainContacts `json:"contacts"`
+			AgreementKeys []string               `json:"agreementKeys"`
+			Period        int                    `json:"period"`
+			Privacy       bool                   `json:"privacy"`
+			RenewAuto     bool                   `json:"renewAuto"`
+		}
+		req := request{}
+		if !readJSON(ctx, w, r, &req, "{domain: string, authCode: string, contacts: object, agreementKeys: [string], period: int, privacy: bool, renewAuto: bool}") {
+			return
+		}
+
+		consent, err := godaddyService.BuildConsent(ctx, req.Domain, godaddy.AgreementOptions{Privacy: req.Privacy, ForTransfer: true}, req.AgreementKeys, clientIP(r))
+		if err != nil {
+			logging.Errorf(ctx, "Error building consent to the transfer of domain %s: %s", req.Domain, err.Error())
+			writeError(ctx, w, err)
+			return
+		}
+
+		orderCtx, cancel := detach(ctx)
+		defer cancel()
+		order, err := godaddyService.TransferDomain(orderCtx, req.Domain, godaddy.TransferRequest{
+			AuthCode:  req.AuthCode,
+			Contacts:  req.Contacts,
+			Consent:   consent,
+			Period:    req.Period,
+			Privacy:   req.Privacy,
+			RenewAuto: req.RenewAuto,
+		})
+		if err != nil {
+			logging.Errorf(ctx, "Error transferring domain %s: %s", req.Domain, err.Error())
+			writeError(ctx, w, err)
+			return
+		}
+
+		writeJSON(ctx, w, http.StatusOK, order)
+	})
+
+	// transfer-status is polled while the state of the transfer is PENDING, UNKNOWN needs to be checked by hand
+	mux.HandleFunc("/transfer-status", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		type request struct {
+			Domain string `json:"domain"`
+		}
+		req := request{}
+		if !readJSON(ctx, w, r, &req, "{domain: string}") {
+			return
+		}
+
+		status, err := godaddyService.GetTransferStatus(ctx, req.Domain)
+		if err != nil {
+			logging.Errorf(ctx, "Error getting transfer status of domain %s: %s", req.Domain, err.Error())
+			writeError(ctx, w, err)
+			return
+		}
+
+		writeJSON(ctx, w, http.StatusOK, status)
+	})
+}